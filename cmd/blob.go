@@ -21,10 +21,9 @@ var blobListCmd = &cobra.Command{
     Use:   "list",
     Short: "List all blob stores",
     Run: func(cmd *cobra.Command, args []string) {
-        blobs, err := nexusClient.ListBlobStores()
+        blobs, err := nexusClient.ListBlobStores(cmd.Context(), listOptions()).All()
         if err != nil {
-            fmt.Printf("Error listing blob stores: %v\n", err)
-            os.Exit(1)
+            fail(err, "Error listing blob stores")
         }
 
         if len(blobs) == 0 {
@@ -34,14 +33,22 @@ var blobListCmd = &cobra.Command{
 
         items := []map[string]interface{}{}
         for _, b := range blobs {
+            quota := ""
+            if b.SoftQuota != nil {
+                quota = fmt.Sprintf("%s %d", b.SoftQuota.Type, b.SoftQuota.Limit)
+            }
             items = append(items, map[string]interface{}{
-                "NAME": b["name"],
-                "TYPE": b["type"],
-                "PATH": b["path"],
+                "NAME":      b.Name,
+                "TYPE":      b.Type,
+                "PATH":      b.Path,
+                "BLOBS":     b.BlobCount,
+                "SIZE":      b.TotalSizeInBytes,
+                "AVAILABLE": b.AvailableSpaceInBytes,
+                "QUOTA":     quota,
             })
         }
 
-        headers := []string{"NAME", "TYPE", "PATH"}
+        headers := []string{"NAME", "TYPE", "PATH", "BLOBS", "SIZE", "AVAILABLE", "QUOTA"}
         output.Render(items, outputFormat, headers, func(r map[string]interface{}) {
             fmt.Printf("\033[33m%s\033[0m\t%s\t%s\n",
                 r["NAME"], r["TYPE"], r["PATH"])
@@ -56,14 +63,13 @@ var blobCreateCmd = &cobra.Command{
     Run: func(cmd *cobra.Command, args []string) {
         name := args[0]
         if blobPath == "" {
-            fmt.Println("Error: --path is required for creating a file blob store.")
+            fmt.Fprintln(os.Stderr, "Error: --path is required for creating a file blob store.")
             _ = cmd.Help()
-            os.Exit(1)
+            os.Exit(ExitUsage)
         }
 
-        if err := nexusClient.CreateBlobStore(name, blobPath); err != nil {
-            fmt.Printf("Error creating blob store '%s': %v\n", name, err)
-            os.Exit(1)
+        if err := nexusClient.CreateBlobStore(cmd.Context(), name, blobPath); err != nil {
+            fail(err, "Error creating blob store '%s'", name)
         }
         fmt.Printf("Blob store '%s' created successfully (path: %s).\n", name, blobPath)
     },
@@ -76,9 +82,8 @@ var blobDeleteCmd = &cobra.Command{
     Run: func(cmd *cobra.Command, args []string) {
         name := args[0]
 
-        if err := nexusClient.DeleteBlobStore(name); err != nil {
-            fmt.Printf("Error deleting blob store '%s': %v\n", name, err)
-            os.Exit(1)
+        if err := nexusClient.DeleteBlobStore(cmd.Context(), name); err != nil {
+            fail(err, "Error deleting blob store '%s'", name)
         }
         fmt.Printf("Blob store '%s' deleted successfully.\n", name)
     },
@@ -91,5 +96,7 @@ func init() {
     blobCmd.AddCommand(blobCreateCmd)
     blobCmd.AddCommand(blobDeleteCmd)
 
+    addListFlags(blobListCmd)
+
     blobCreateCmd.Flags().StringVar(&blobPath, "path", "", "Filesystem path for the file blob store (required)")
 }
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "text/tabwriter"
+    "nexuscli/internal/client"
+    "nexuscli/internal/output"
+    "github.com/spf13/cobra"
+    "gopkg.in/yaml.v3"
+)
+
+var (
+    componentGroup   string
+    componentName    string
+    componentVersion string
+)
+
+var componentCmd = &cobra.Command{
+    Use:   "component",
+    Short: "Browse the components of Nexus repositories",
+    Long:  `Allows listing the components of a repository and showing a component with its assets.`,
+}
+
+var componentListCmd = &cobra.Command{
+    Use:   "list <repo_name>",
+    Short: "List the components of a repository",
+    Long: `List the components of a repository with their assets. Results are
+printed page by page as Nexus returns them, so large repositories start
+showing output right away; with -o json the array is streamed as well,
+and left unclosed if a later page fails, so the output does not parse.
+
+--group, --name and --version take a glob such as "1.2.*", or a regular
+expression between slashes. Nexus cannot filter the list itself, so every
+page is still fetched; --limit stops once enough components matched.`,
+    Example: `  nexuscli component list maven-releases --group 'com.acme*' --version '2.*'
+  nexuscli component list npm-hosted --name '/^@acme\//' -o json
+  nexuscli component list docker-hosted --name 'team/*' --limit 20`,
+    Args: cobra.ExactArgs(1),
+    Run: func(cmd *cobra.Command, args []string) {
+        repoName := args[0]
+
+        var filters []func(client.Component) bool
+        for _, f := range []struct {
+            flag, pattern string
+            field         func(client.Component) string
+        }{
+            {"--group", componentGroup, func(c client.Component) string { return c.Group }},
+            {"--name", componentName, func(c client.Component) string { return c.Name }},
+            {"--version", componentVersion, func(c client.Component) string { return c.Version }},
+        } {
+            match, err := nameMatcher(f.flag, f.pattern)
+            if err != nil {
+                fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+                os.Exit(ExitUsage)
+            }
+            field := f.field
+            filters = append(filters, func(c client.Component) bool { return match(field(c)) })
+        }
+
+        // the limit counts matches, so the pager itself is not limited
+        pager := nexusClient.ListComponents(cmd.Context(), repoName, client.ListOptions{PageSize: listPageSize})
+        out := newComponentStream(os.Stdout, outputFormat)
+        count := 0
+        for pager.Next() {
+            c := pager.Item()
+            matched := true
+            for _, f := range filters {
+                matched = matched && f(c)
+            }
+            if matched {
+                out.write(c)
+                count++
+            }
+            if listLimit > 0 && count >= listLimit {
+                pager.Stop()
+            }
+            if pager.Buffered() == 0 {
+                out.flush()
+            }
+        }
+        if err := pager.Err(); err != nil {
+            // a JSON array is left open, so truncated output does not parse
+            out.flush()
+            fail(err, "Error listing components of '%s'", repoName)
+        }
+        out.close()
+        if count == 0 && out.tabular() {
+            fmt.Println("No components found.")
+        }
+    },
+}
+
+var componentGetCmd = &cobra.Command{
+    Use:   "get <component_id>",
+    Short: "Show a component and its assets",
+    Long: `Show a component and its assets. The ID is the one component list prints
+(or the "id" of -o json).`,
+    Args: cobra.ExactArgs(1),
+    Run: func(cmd *cobra.Command, args []string) {
+        id := args[0]
+
+        c, err := nexusClient.GetComponent(cmd.Context(), id)
+        if err != nil {
+            fail(err, "Error reading component '%s'", id)
+        }
+
+        switch strings.ToLower(outputFormat) {
+        case "json":
+            data, _ := json.MarshalIndent(c, "", "  ")
+            fmt.Println(string(data))
+            return
+        case "yaml", "yml":
+            doc, _ := plainDocument(c)
+            data, _ := yaml.Marshal(doc)
+            fmt.Print(string(data))
+            return
+        }
+
+        w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+        for _, kv := range [][2]string{
+            {"ID", c.ID}, {"REPOSITORY", c.Repository}, {"FORMAT", c.Format},
+            {"GROUP", c.Group}, {"NAME", c.Name}, {"VERSION", c.Version},
+        } {
+            fmt.Fprintf(w, "%s:\t%s\n", kv[0], kv[1])
+        }
+        w.Flush()
+        if len(c.Assets) == 0 {
+            return
+        }
+
+        fmt.Println()
+        assets := []map[string]interface{}{}
+        for _, a := range c.Assets {
+            assets = append(assets, map[string]interface{}{
+                "PATH":         a.Path,
+                "SIZE":         humanBytes(a.FileSize),
+                "CONTENT TYPE": a.ContentType,
+                "UPLOADED":     a.BlobCreated,
+                "UPLOADER":     a.Uploader,
+                "SHA1":         a.Checksum["sha1"],
+            })
+        }
+        output.Render(assets, "table", []string{"PATH", "SIZE", "CONTENT TYPE", "UPLOADED", "UPLOADER", "SHA1"}, nil)
+    },
+}
+
+// componentStream writes components as they arrive. Tables are aligned
+// per page; JSON is written as one array and YAML as one sequence.
+type componentStream struct {
+    out    io.Writer
+    format string
+    table  *tabwriter.Writer
+    items  int
+}
+
+func newComponentStream(out io.Writer, format string) *componentStream {
+    s := &componentStream{out: out, format: strings.ToLower(format)}
+    if s.tabular() {
+        s.table = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+    }
+    return s
+}
+
+func (s *componentStream) tabular() bool {
+    switch s.format {
+    case "json", "yaml", "yml", "color":
+        return false
+    }
+    return true
+}
+
+func (s *componentStream) write(c client.Component) {
+    var size int64
+    for _, a := range c.Assets {
+        size += a.FileSize
+    }
+
+    switch s.format {
+    case "json":
+        data, _ := json.MarshalIndent(c, "  ", "  ")
+        sep := "[\n  "
+        if s.items > 0 {
+            sep = ",\n  "
+        }
+        fmt.Fprint(s.out, sep+string(data))
+    case "yaml", "yml":
+        doc, _ := plainDocument(c)
+        data, _ := yaml.Marshal([]interface{}{doc})
+        fmt.Fprint(s.out, string(data))
+    case "color":
+        fmt.Fprintf(s.out, "\033[36m%s\033[0m\t%s\t%s\t%s\t%d assets\t%s\n",
+            c.Group, c.Name, c.Version, c.Format, len(c.Assets), c.ID)
+    default:
+        if s.items == 0 {
+            fmt.Fprintln(s.table, "GROUP\tNAME\tVERSION\tFORMAT\tASSETS\tSIZE\tID")
+        }
+        fmt.Fprintf(s.table, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+            c.Group, c.Name, c.Version, c.Format, len(c.Assets), humanBytes(size), c.ID)
+    }
+    s.items++
+}
+
+// flush prints the rows of the page that is complete.
+func (s *componentStream) flush() {
+    if s.table != nil {
+        s.table.Flush()
+    }
+}
+
+func (s *componentStream) close() {
+    s.flush()
+    if s.format == "json" {
+        if s.items == 0 {
+            fmt.Fprintln(s.out, "[]")
+            return
+        }
+        fmt.Fprintln(s.out, "\n]")
+    }
+}
+
+// plainDocument turns v into maps and slices through its JSON encoding,
+// so YAML uses the JSON field names and keeps unknown fields.
+func plainDocument(v interface{}) (interface{}, error) {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return nil, err
+    }
+    var doc interface{}
+    err = json.Unmarshal(data, &doc)
+    return doc, err
+}
+
+func init() {
+    rootCmd.AddCommand(componentCmd)
+    componentCmd.AddCommand(componentListCmd)
+    componentCmd.AddCommand(componentGetCmd)
+
+    addListFlags(componentListCmd)
+    componentListCmd.Flags().StringVar(&componentGroup, "group", "", "Only list components whose group matches this glob or /regular expression/")
+    componentListCmd.Flags().StringVar(&componentName, "name", "", "Only list components whose name matches this glob or /regular expression/")
+    componentListCmd.Flags().StringVar(&componentVersion, "version", "", "Only list components whose version matches this glob or /regular expression/")
+}
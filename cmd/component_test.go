@@ -0,0 +1,84 @@
+package cmd
+
+import (
+    "bytes"
+    "encoding/json"
+    "strings"
+    "testing"
+    "nexuscli/internal/client"
+    "gopkg.in/yaml.v3"
+)
+
+func testComponents() []client.Component {
+    return []client.Component{
+        {ID: "1", Repository: "r", Format: "maven2", Group: "com.acme", Name: "app", Version: "1.0",
+            Assets: []client.Asset{{Path: "app-1.0.jar", FileSize: 2048}, {Path: "app-1.0.pom", FileSize: 512}}},
+        {ID: "2", Repository: "r", Format: "maven2", Group: "com.acme", Name: "lib", Version: "2.1"},
+    }
+}
+
+func TestComponentStream(t *testing.T) {
+    tests := []struct {
+        format string
+        items  int
+        check  func(t *testing.T, out string)
+    }{
+        {"json", 2, func(t *testing.T, out string) {
+            var got []client.Component
+            if err := json.Unmarshal([]byte(out), &got); err != nil {
+                t.Fatalf("not a JSON array: %v\n%s", err, out)
+            }
+            if len(got) != 2 || got[0].ID != "1" || got[1].ID != "2" {
+                t.Errorf("got %+v", got)
+            }
+        }},
+        {"json", 0, func(t *testing.T, out string) {
+            if out != "[]\n" {
+                t.Errorf("got %q, want an empty array", out)
+            }
+        }},
+        {"yaml", 2, func(t *testing.T, out string) {
+            var got []map[string]interface{}
+            if err := yaml.Unmarshal([]byte(out), &got); err != nil {
+                t.Fatalf("not a YAML sequence: %v\n%s", err, out)
+            }
+            if len(got) != 2 || got[1]["name"] != "lib" {
+                t.Errorf("got %v", got)
+            }
+        }},
+        {"table", 2, func(t *testing.T, out string) {
+            lines := strings.Split(strings.TrimSpace(out), "\n")
+            if len(lines) != 3 || !strings.HasPrefix(lines[0], "GROUP") {
+                t.Fatalf("got\n%s", out)
+            }
+            if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "com.acme app 1.0 maven2 2 2.5 KiB 1" {
+                t.Errorf("row %q", lines[1])
+            }
+        }},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.format, func(t *testing.T) {
+            var out bytes.Buffer
+            s := newComponentStream(&out, tt.format)
+            for _, c := range testComponents()[:tt.items] {
+                s.write(c)
+            }
+            s.close()
+            tt.check(t, out.String())
+        })
+    }
+}
+
+func TestComponentStreamTruncated(t *testing.T) {
+    var out bytes.Buffer
+    s := newComponentStream(&out, "json")
+    s.write(testComponents()[0])
+    // the next page failed: flushed, but not closed
+    s.flush()
+
+    var got []client.Component
+    if err := json.Unmarshal(out.Bytes(), &got); err == nil {
+        t.Errorf("truncated output parses as %+v", got)
+    }
+}
@@ -1,9 +1,19 @@
 package cmd
 
 import (
+    "bufio"
+    "bytes"
+    "errors"
     "fmt"
+    "io/fs"
     "os"
+    "os/exec"
+    "runtime"
+    "strings"
     "nexuscli/config"
+    "nexuscli/internal/client"
+    "nexuscli/internal/credentials"
+    "nexuscli/internal/output"
     "github.com/spf13/cobra"
     "github.com/spf13/viper"
 )
@@ -13,65 +23,586 @@ var (
     cfgUsername string
     cfgPassword string
     cfgToken string
+    cfgCredentialStore string
+    cfgCredentialHelper string
     cfgTimeout int
+    cfgRetries int
+    cfgRetryMaxWait int
+    cfgInsecure bool
+    cfgCACert string
+    cfgClientCert string
+    cfgClientKey string
+    cfgTLSMinVersion string
+    cfgProxy string
+    cfgProxyUsername string
+    cfgProxyPassword string
+    cfgNoProxy []string
+    cfgReveal bool
+    cfgUntrust bool
 )
 
 var configCmd = &cobra.Command{
     Use:   "config",
     Short: "Manage Nexus CLI configuration",
+    Long: `Manage Nexus CLI configuration.
+
+Settings are resolved in this order, later ones winning:
+  1. defaults
+  2. the user config file: --config/NEXUS_CONFIG if given, otherwise
+     $XDG_CONFIG_HOME/nexuscli/config.yaml or the legacy ~/.nexuscli.yaml
+  3. the nearest .nexuscli.yml in the working directory or its parents
+     (skipped when --config/NEXUS_CONFIG is given); it may only set
+     timeout, retries, retryMaxWait, logFormat and currentContext, and
+     after "config trust-project" url, username, password, token and
+     insecureSkipVerify
+  4. the active context (--context, NEXUS_CONTEXT or currentContext)
+  5. NEXUS_* environment variables
+  6. command line flags
+
+Commands that change the configuration write to the user config file.`,
 }
 
 var configViewCmd = &cobra.Command{
     Use:   "view",
     Short: "View current configuration",
     Run: func(cmd *cobra.Command, args []string) {
+        if config.ActiveContext != "" {
+            fmt.Printf("Context: %s\n", config.ActiveContext)
+        } else {
+            fmt.Println("Context: (none)")
+        }
         fmt.Printf("URL: %s\n", viper.GetString("url"))
         fmt.Printf("Username: %s\n", viper.GetString("username"))
-        fmt.Printf("Password: %s\n", mask(viper.GetString("password")))
-        fmt.Printf("Token: %s\n", mask(viper.GetString("token")))
+        if err := config.ResolveCredentials(); err != nil {
+            fmt.Fprintf(os.Stderr, "Warning: could not resolve credentials: %v\n", err)
+        }
+        fmt.Printf("Password: %s\n", mask(config.Global.Password))
+        fmt.Printf("Token: %s\n", mask(config.Global.Token))
+        fmt.Printf("Credential store: %s\n", viper.GetString("credentialStore"))
+        fmt.Printf("Credential helper: %s\n", viper.GetString("credentialHelper"))
         fmt.Printf("Timeout: %d\n", viper.GetInt("timeout"))
+        fmt.Printf("Retries: %d\n", viper.GetInt("retries"))
+        fmt.Printf("Retry max wait: %d\n", viper.GetInt("retryMaxWait"))
+        fmt.Printf("Insecure skip verify: %t\n", viper.GetBool("insecureSkipVerify"))
+        fmt.Printf("CA cert: %s\n", viper.GetString("caCert"))
+        fmt.Printf("Client cert: %s\n", viper.GetString("clientCert"))
+        fmt.Printf("Client key: %s\n", viper.GetString("clientKey"))
+        fmt.Printf("TLS min version: %s\n", viper.GetString("tlsMinVersion"))
+        fmt.Printf("Proxy: %s\n", viper.GetString("proxy"))
+        fmt.Printf("Proxy username: %s\n", viper.GetString("proxyUsername"))
+        fmt.Printf("Proxy password: %s\n", mask(viper.GetString("proxyPassword")))
+        fmt.Printf("No proxy: %s\n", strings.Join(viper.GetStringSlice("noProxy"), ","))
     },
 }
 
 var configSetCmd = &cobra.Command{
     Use:   "set",
     Short: "Set configuration values",
+    Long: `Set configuration values. Values are written to the active context
+(see "config use-context"), to the one named with --context, or to the
+top level of the config file when no context is in use.`,
     Run: func(cmd *cobra.Command, args []string) {
+        f, err := config.LoadFile()
+        if err != nil {
+            fail(err, "Error loading config")
+        }
+        target := config.ActiveContext
+
         if cfgURL != "" {
-            viper.Set("url", cfgURL)
+            f.Set(target, "url", cfgURL)
         }
         if cfgUsername != "" {
-            viper.Set("username", cfgUsername)
+            f.Set(target, "username", cfgUsername)
+        }
+        if cfgCredentialStore != "" {
+            f.Set(target, "credentialStore", cfgCredentialStore)
+        }
+        if cfgCredentialHelper != "" {
+            f.Set(target, "credentialHelper", cfgCredentialHelper)
         }
         if cfgPassword != "" {
-            viper.Set("password", cfgPassword)
+            if err := storeSecret(f, target, "password", cfgPassword); err != nil {
+                fail(err, "Error storing password")
+            }
         }
         if cfgToken != "" {
-            viper.Set("token", cfgToken)
+            if err := storeSecret(f, target, "token", cfgToken); err != nil {
+                fail(err, "Error storing token")
+            }
         }
         if cfgTimeout > 0 {
-            viper.Set("timeout", cfgTimeout)
+            f.Set(target, "timeout", cfgTimeout)
+        }
+        if cmd.Flags().Changed("retries") {
+            f.Set(target, "retries", cfgRetries)
+        }
+        if cfgRetryMaxWait > 0 {
+            f.Set(target, "retryMaxWait", cfgRetryMaxWait)
+        }
+        if cmd.Flags().Changed("insecure-skip-verify") {
+            f.Set(target, "insecureSkipVerify", cfgInsecure)
+        }
+        if err := checkTLSFiles(cmd); err != nil {
+            fail(usageError{err}, "Error")
+        }
+        for flag, key := range map[string]string{"ca-cert": "caCert", "client-cert": "clientCert", "client-key": "clientKey"} {
+            value, _ := cmd.Flags().GetString(flag)
+            switch {
+            case !cmd.Flags().Changed(flag):
+            case value == "":
+                f.Delete(target, key)
+            default:
+                f.Set(target, key, value)
+            }
+        }
+        if cfgTLSMinVersion != "" {
+            f.Set(target, "tlsMinVersion", cfgTLSMinVersion)
+        }
+        if cfgProxy != "" {
+            f.Set(target, "proxy", cfgProxy)
+        }
+        if cfgProxyUsername != "" {
+            f.Set(target, "proxyUsername", cfgProxyUsername)
+        }
+        if cfgProxyPassword != "" {
+            f.Set(target, "proxyPassword", cfgProxyPassword)
+        }
+        if cmd.Flags().Changed("no-proxy") {
+            f.Set(target, "noProxy", cfgNoProxy)
         }
 
-        if err := config.SaveConfig(); err != nil {
-            fmt.Printf("Error saving config: %v\n", err)
-            os.Exit(1)
+        if err := f.Save(); err != nil {
+            fail(err, "Error saving config")
         }
 
+        if target != "" {
+            fmt.Printf("Configuration of context '%s' updated successfully.\n", target)
+            return
+        }
         fmt.Println("Configuration updated successfully.")
     },
 }
 
+// checkTLSFiles loads the CA bundle and client certificate files given to
+// config set, so a broken path is rejected instead of breaking every later
+// command. A certificate set without its key is checked against the key
+// already configured.
+func checkTLSFiles(cmd *cobra.Command) error {
+    opts := client.TLSOptions{CertFile: config.Global.ClientCert, KeyFile: config.Global.ClientKey}
+    if cmd.Flags().Changed("ca-cert") {
+        opts.CAFile = cfgCACert
+    }
+    if cmd.Flags().Changed("client-cert") {
+        opts.CertFile = cfgClientCert
+    }
+    if cmd.Flags().Changed("client-key") {
+        opts.KeyFile = cfgClientKey
+    }
+    if !cmd.Flags().Changed("client-cert") && !cmd.Flags().Changed("client-key") {
+        opts.CertFile, opts.KeyFile = "", ""
+    }
+    return opts.Validate()
+}
+
+var configGetContextsCmd = &cobra.Command{
+    Use:   "get-contexts",
+    Short: "List the contexts in the config file",
+    Run: func(cmd *cobra.Command, args []string) {
+        f, err := config.LoadFile()
+        if err != nil {
+            fail(err, "Error loading config")
+        }
+
+        names := f.Contexts()
+        if len(names) == 0 {
+            fmt.Println("No contexts found.")
+            return
+        }
+
+        items := []map[string]interface{}{}
+        for _, name := range names {
+            section := f.Section(name, false)
+            current := ""
+            if name == config.ActiveContext {
+                current = "*"
+            }
+            items = append(items, map[string]interface{}{
+                "CURRENT":  current,
+                "NAME":     name,
+                "URL":      section["url"],
+                "USERNAME": section["username"],
+            })
+        }
+
+        headers := []string{"CURRENT", "NAME", "URL", "USERNAME"}
+        output.Render(items, outputFormat, headers, func(c map[string]interface{}) {
+            fmt.Printf("%s\t\033[32m%s\033[0m\t%v\n", c["CURRENT"], c["NAME"], c["URL"])
+        })
+    },
+}
+
+var configUseContextCmd = &cobra.Command{
+    Use:   "use-context <name>",
+    Short: "Select the context used by default",
+    Args:  cobra.ExactArgs(1),
+    Run: func(cmd *cobra.Command, args []string) {
+        name := args[0]
+
+        f, err := config.LoadFile()
+        if err != nil {
+            fail(err, "Error loading config")
+        }
+        if !f.HasContext(name) {
+            fmt.Fprintf(os.Stderr, "Error: context '%s' not found. Create it with \"config set --context %s\".\n", name, name)
+            os.Exit(ExitNotFound)
+        }
+
+        f.SetCurrentContext(name)
+        if err := f.Save(); err != nil {
+            fail(err, "Error saving config")
+        }
+        fmt.Printf("Switched to context '%s'.\n", name)
+    },
+}
+
+var configTrustProjectCmd = &cobra.Command{
+    Use:   "trust-project [file]",
+    Short: "Let a project-local config file set the server and credentials",
+    Long: `Let the project-local .nexuscli.yml found from the working directory, or
+the file given, also set url, username, password, token and
+insecureSkipVerify. Only trust files you wrote or checked: they decide where
+your credentials are sent. The trust lapses when the file changes;
+--remove takes it back.`,
+    Args: cobra.MaximumNArgs(1),
+    Run: func(cmd *cobra.Command, args []string) {
+        path := config.ProjectFile
+        if len(args) == 1 {
+            path = args[0]
+        }
+        if path == "" {
+            fmt.Fprintln(os.Stderr, "Error: no project-local config file found; pass its path.")
+            os.Exit(ExitUsage)
+        }
+
+        if err := config.TrustProjectFile(path, !cfgUntrust); err != nil {
+            fail(err, "Error")
+        }
+        if cfgUntrust {
+            fmt.Printf("%s is no longer trusted.\n", path)
+            return
+        }
+        fmt.Printf("%s is trusted until it changes.\n", path)
+    },
+}
+
+var configMigrateCmd = &cobra.Command{
+    Use:   "migrate [file]",
+    Short: "Rewrite a config file in the old nested schema to the flat one",
+    Long: `Rewrite a config file that uses the nested "nexus:" section and keys such
+as timeoutSeconds to the flat schema. Defaults to the user config file; pass
+a path to migrate another one, e.g. a project-local .nexuscli.yml. The
+original is kept with a .bak suffix.`,
+    Args: cobra.MaximumNArgs(1),
+    Run: func(cmd *cobra.Command, args []string) {
+        path := ""
+        if len(args) == 1 {
+            path = args[0]
+        } else {
+            p, err := config.FilePath()
+            if err != nil {
+                fail(err, "Error locating config")
+            }
+            path = p
+        }
+
+        changed, err := config.MigrateFile(path)
+        if err != nil {
+            fail(err, "Error migrating config")
+        }
+        if !changed {
+            fmt.Printf("%s already uses the current schema.\n", path)
+            return
+        }
+        fmt.Printf("%s migrated (backup: %s.bak).\n", path, path)
+    },
+}
+
+var configGetCmd = &cobra.Command{
+    Use:   "get <key>",
+    Short: "Print the effective value of a configuration key",
+    Long: `Print the effective value of a configuration key, after config files,
+the active context, NEXUS_* variables and flags have been applied. Keys
+may be given as in the config file (retryMaxWait) or in flag form
+(retry-max-wait). Secrets are masked unless --reveal is given.`,
+    Args: cobra.ExactArgs(1),
+    Run: func(cmd *cobra.Command, args []string) {
+        key, ok := config.LookupKey(args[0])
+        if !ok {
+            fmt.Fprintf(os.Stderr, "Error: unknown key '%s'. Valid keys: %s\n", args[0], strings.Join(config.Keys(), ", "))
+            os.Exit(ExitUsage)
+        }
+
+        var value interface{}
+        switch key {
+        case "password", "token":
+            if err := config.ResolveCredentials(); err != nil {
+                fail(err, "Error resolving credentials")
+            }
+            secret := config.Global.Password
+            if key == "token" {
+                secret = config.Global.Token
+            }
+            if !cfgReveal {
+                secret = mask(secret)
+            }
+            value = secret
+        case "proxyPassword":
+            value = viper.GetString(key)
+            if !cfgReveal {
+                value = mask(viper.GetString(key))
+            }
+        case "noProxy":
+            value = strings.Join(viper.GetStringSlice(key), ",")
+        default:
+            value = viper.Get(key)
+        }
+        fmt.Println(value)
+    },
+}
+
+var configUnsetCmd = &cobra.Command{
+    Use:   "unset <key>",
+    Short: "Remove a configuration key",
+    Long: `Remove a key from the active context, from the one named with --context,
+or from the top level of the config file when no context is in use. The
+password and token are removed from the credential store as well.`,
+    Args: cobra.ExactArgs(1),
+    Run: func(cmd *cobra.Command, args []string) {
+        key, ok := config.LookupKey(args[0])
+        if !ok {
+            fmt.Fprintf(os.Stderr, "Error: unknown key '%s'. Valid keys: %s\n", args[0], strings.Join(config.Keys(), ", "))
+            os.Exit(ExitUsage)
+        }
+
+        f, err := config.LoadFile()
+        if err != nil {
+            fail(err, "Error loading config")
+        }
+        target := config.ActiveContext
+
+        section := f.Section(target, false)
+        _, found := section[key]
+        if key == "password" || key == "token" {
+            erased, err := eraseSecret(f, target, key)
+            if err != nil {
+                fail(err, "Error removing %s", key)
+            }
+            found = found || erased
+        }
+        if !found {
+            fmt.Printf("'%s' is not set.\n", key)
+            return
+        }
+
+        f.Delete(target, key)
+        if err := f.Save(); err != nil {
+            fail(err, "Error saving config")
+        }
+        if target != "" {
+            fmt.Printf("'%s' removed from context '%s'.\n", key, target)
+            return
+        }
+        fmt.Printf("'%s' removed.\n", key)
+    },
+}
+
+var configEditCmd = &cobra.Command{
+    Use:   "edit",
+    Short: "Edit the config file in $EDITOR",
+    Long: `Open the user config file in $VISUAL or $EDITOR (vi if neither is set).
+The file is validated when the editor exits; if it is invalid you can go
+back to fix it, otherwise nothing is written.`,
+    Args: cobra.NoArgs,
+    Run: func(cmd *cobra.Command, args []string) {
+        path, err := config.FilePath()
+        if err != nil {
+            fail(err, "Error locating config")
+        }
+        original, err := os.ReadFile(path)
+        if err != nil && !errors.Is(err, fs.ErrNotExist) {
+            fail(err, "Error reading config")
+        }
+
+        tmp, err := os.CreateTemp("", "nexuscli-config-*.yaml")
+        if err != nil {
+            fail(err, "Error creating temporary file")
+        }
+        defer os.Remove(tmp.Name())
+        if _, err := tmp.Write(original); err != nil {
+            fail(err, "Error creating temporary file")
+        }
+        tmp.Close()
+
+        for {
+            if err := runEditor(tmp.Name()); err != nil {
+                fail(err, "Error running editor")
+            }
+            edited, err := os.ReadFile(tmp.Name())
+            if err != nil {
+                fail(err, "Error reading edited config")
+            }
+            if bytes.Equal(edited, original) {
+                fmt.Println("Edit cancelled, no changes made.")
+                return
+            }
+
+            err = config.WriteRaw(path, edited)
+            if err == nil {
+                fmt.Printf("%s updated successfully.\n", path)
+                return
+            }
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            if !confirm("Edit again?", true) {
+                fmt.Fprintln(os.Stderr, "Changes discarded.")
+                os.Exit(exitCode(err))
+            }
+        }
+    },
+}
+
 func init() {
     rootCmd.AddCommand(configCmd)
     configCmd.AddCommand(configViewCmd)
     configCmd.AddCommand(configSetCmd)
+    configCmd.AddCommand(configGetContextsCmd)
+    configCmd.AddCommand(configUseContextCmd)
+    configCmd.AddCommand(configMigrateCmd)
+    configCmd.AddCommand(configGetCmd)
+    configCmd.AddCommand(configUnsetCmd)
+    configCmd.AddCommand(configEditCmd)
+    configCmd.AddCommand(configTrustProjectCmd)
+
+    configGetCmd.Flags().BoolVar(&cfgReveal, "reveal", false, "Print secrets in plain text")
+    configTrustProjectCmd.Flags().BoolVar(&cfgUntrust, "remove", false, "Stop trusting the file")
 
     configSetCmd.Flags().StringVar(&cfgURL, "url", "", "Nexus server URL")
     configSetCmd.Flags().StringVar(&cfgUsername, "username", "", "Nexus username")
     configSetCmd.Flags().StringVar(&cfgPassword, "password", "", "Nexus password")
     configSetCmd.Flags().StringVar(&cfgToken, "token", "", "Nexus API token")
+    configSetCmd.Flags().StringVar(&cfgCredentialStore, "credential-store", "",
+        "Where to keep the password and token: keyring, file (encrypted) or plain (this config file)")
+    configSetCmd.Flags().StringVar(&cfgCredentialHelper, "credential-helper", "",
+        "External program (nexuscli-credential-<name>) that supplies the credentials")
     configSetCmd.Flags().IntVar(&cfgTimeout, "timeout", 0, "Request timeout in seconds")
+    configSetCmd.Flags().IntVar(&cfgRetries, "retries", 0, "Number of retries for transient failures")
+    configSetCmd.Flags().IntVar(&cfgRetryMaxWait, "retry-max-wait", 0, "Maximum wait between retries in seconds")
+    configSetCmd.Flags().BoolVar(&cfgInsecure, "insecure-skip-verify", false, "Skip TLS certificate verification (insecure)")
+    configSetCmd.Flags().StringVar(&cfgCACert, "ca-cert", "", "Path to a PEM CA bundle (\"\" removes it)")
+    configSetCmd.Flags().StringVar(&cfgClientCert, "client-cert", "", "Path to a PEM client certificate (\"\" removes it)")
+    configSetCmd.Flags().StringVar(&cfgClientKey, "client-key", "", "Path to the client certificate's private key (\"\" removes it)")
+    configSetCmd.Flags().StringVar(&cfgTLSMinVersion, "tls-min-version", "", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3")
+    configSetCmd.Flags().StringVar(&cfgProxy, "proxy", "", "Proxy URL, or \"direct\" to bypass HTTPS_PROXY")
+    configSetCmd.Flags().StringVar(&cfgProxyUsername, "proxy-username", "", "Proxy username")
+    configSetCmd.Flags().StringVar(&cfgProxyPassword, "proxy-password", "", "Proxy password")
+    configSetCmd.Flags().StringSliceVar(&cfgNoProxy, "no-proxy", nil, "Comma-separated hosts, domains or CIDRs reached without the proxy")
+}
+
+// storeSecret keeps a password or token in the credential store of the
+// target context and drops any plain text copy from the config file. The
+// store picked on first use is recorded so later runs look there.
+func storeSecret(f *config.File, target, kind, secret string) error {
+    backend, _ := f.Section(target, true)["credentialStore"].(string)
+    if backend == "" {
+        backend = viper.GetString("credentialStore")
+    }
+    if backend == credentials.BackendPlain {
+        f.Set(target, kind, secret)
+        return nil
+    }
+
+    store, err := credentials.Open(backend)
+    if err != nil {
+        return err
+    }
+    if err := store.Set(credentials.Account(target, kind), secret); err != nil {
+        return err
+    }
+    f.Set(target, "credentialStore", store.Name())
+    f.Delete(target, kind)
+    fmt.Printf("The %s is kept in the %s credential store.\n", kind, store.Name())
+    return nil
+}
+
+// eraseSecret removes a password or token of the target context from its
+// credential store. It reports whether anything was stored.
+func eraseSecret(f *config.File, target, kind string) (bool, error) {
+    backend, _ := f.Section(target, false)["credentialStore"].(string)
+    if backend == "" {
+        backend = viper.GetString("credentialStore")
+    }
+    if backend == "" || backend == credentials.BackendPlain {
+        return false, nil
+    }
+    store, err := credentials.Open(backend)
+    if err != nil {
+        return false, err
+    }
+    account := credentials.Account(target, kind)
+    if _, err := store.Get(account); errors.Is(err, credentials.ErrNotFound) {
+        return false, nil
+    }
+    if err := store.Delete(account); err != nil {
+        return false, err
+    }
+    return true, nil
+}
+
+// runEditor opens path in the user's editor. $VISUAL and $EDITOR may hold
+// arguments, e.g. "code --wait".
+func runEditor(path string) error {
+    editor := os.Getenv("VISUAL")
+    if editor == "" {
+        editor = os.Getenv("EDITOR")
+    }
+    if editor == "" {
+        editor = "vi"
+        if runtime.GOOS == "windows" {
+            editor = "notepad"
+        }
+    }
+    fields := strings.Fields(editor)
+    c := exec.Command(fields[0], append(fields[1:], path)...)
+    c.Stdin = os.Stdin
+    c.Stdout = os.Stdout
+    c.Stderr = os.Stderr
+    return c.Run()
+}
+
+// stdin is shared by every prompt, so input one prompt buffered ahead is
+// still there for the next.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on stderr and reads the answer from
+// stdin. An empty answer picks def; EOF or a read error is a no, so a
+// closed or non-interactive stdin never loops on a question.
+func confirm(question string, def bool) bool {
+    hint := "[y/N]"
+    if def {
+        hint = "[Y/n]"
+    }
+    fmt.Fprintf(os.Stderr, "%s %s ", question, hint)
+    answer, err := stdin.ReadString('\n')
+    if err != nil && answer == "" {
+        fmt.Fprintln(os.Stderr)
+        return false
+    }
+    switch strings.ToLower(strings.TrimSpace(answer)) {
+    case "y", "yes":
+        return true
+    case "n", "no":
+        return false
+    }
+    return def
 }
 
 func mask(s string) string {
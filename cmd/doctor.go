@@ -0,0 +1,501 @@
+package cmd
+
+import (
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net"
+    "net/url"
+    "os"
+    "strings"
+    "time"
+    "nexuscli/config"
+    "nexuscli/internal/client"
+    "nexuscli/internal/output"
+    "github.com/spf13/cobra"
+    "github.com/spf13/viper"
+    "gopkg.in/yaml.v3"
+)
+
+// Results of a doctor check.
+const (
+    checkPass = "pass"
+    checkWarn = "warn"
+    checkFail = "fail"
+    checkSkip = "skip"
+)
+
+type doctorSetting struct {
+    Key    string `json:"key" yaml:"key"`
+    Value  string `json:"value" yaml:"value"`
+    Source string `json:"source" yaml:"source"`
+}
+
+type doctorCheck struct {
+    Name   string `json:"name" yaml:"name"`
+    Status string `json:"status" yaml:"status"`
+    Detail string `json:"detail" yaml:"detail"`
+    err    error
+}
+
+type doctorReport struct {
+    Context  string          `json:"context" yaml:"context"`
+    Settings []doctorSetting `json:"settings" yaml:"settings"`
+    Checks   []doctorCheck   `json:"checks" yaml:"checks"`
+    OK       bool            `json:"ok" yaml:"ok"`
+}
+
+var configDoctorCmd = &cobra.Command{
+    Use:   "doctor",
+    Short: "Diagnose connectivity, authentication and the effective settings",
+    Long: `Show the effective value of every setting and where it comes from (flag,
+NEXUS_* environment variable, config file or default), then check in turn:
+the config files, DNS resolution and TCP reachability of the server, the
+TLS handshake and certificate chain, authentication, the server version
+and edition, and whether the node accepts writes.
+
+The exit code is 0 when no check failed, otherwise the code of the first
+failure (see "nexuscli --help").`,
+    Args: cobra.NoArgs,
+    Run: func(cmd *cobra.Command, args []string) {
+        report := runDoctor(cmd)
+        printDoctorReport(report)
+        if code := report.exitCode(); code != ExitOK {
+            os.Exit(code)
+        }
+    },
+}
+
+// runDoctor runs every check in turn. A check that cannot run because an
+// earlier one failed is skipped.
+func runDoctor(cmd *cobra.Command) doctorReport {
+    ctx := cmd.Context()
+    report := doctorReport{Context: config.ActiveContext, OK: true}
+
+    credErr := config.ResolveCredentials()
+    report.Settings = doctorSettings(cmd)
+
+    add := func(c doctorCheck) {
+        if c.Status == checkFail {
+            report.OK = false
+        }
+        report.Checks = append(report.Checks, c)
+    }
+
+    add(checkConfigFiles())
+    if credErr != nil {
+        add(doctorCheck{Name: "Credentials", Status: checkFail, Detail: credErr.Error(), err: credErr})
+    }
+
+    target, urlCheck := checkURL(config.Global.URL)
+    add(urlCheck)
+
+    c, err := newClient(config.Global)
+    if err != nil {
+        add(doctorCheck{Name: "Client", Status: checkFail, Detail: err.Error(), err: err})
+    }
+
+    if target == nil || c == nil {
+        for _, name := range []string{"DNS", "TCP", "TLS", "Authentication", "Server", "Writable"} {
+            add(doctorCheck{Name: name, Status: checkSkip, Detail: "no usable URL or client settings"})
+        }
+        return report
+    }
+
+    timeout := time.Duration(config.Global.Timeout) * time.Second
+    proxy, err := c.ProxyFor(target.String())
+    if err != nil {
+        add(doctorCheck{Name: "Proxy", Status: checkFail, Detail: err.Error(), err: err})
+    } else if proxy != nil {
+        add(doctorCheck{Name: "Proxy", Status: checkPass, Detail: "requests go through " + proxy.Redacted()})
+    }
+
+    dial := target
+    if proxy != nil {
+        dial = proxy
+    }
+    dns := checkDNS(ctx, dial, timeout)
+    add(dns)
+    if dns.Status == checkFail {
+        add(doctorCheck{Name: "TCP", Status: checkSkip, Detail: "host does not resolve"})
+    } else {
+        add(checkTCP(ctx, dial, timeout))
+    }
+
+    switch {
+    case target.Scheme != "https":
+        add(doctorCheck{Name: "TLS", Status: checkWarn, Detail: "plain HTTP: credentials are sent unencrypted"})
+    case proxy != nil:
+        add(doctorCheck{Name: "TLS", Status: checkSkip, Detail: "not checked through a proxy; see Authentication"})
+    default:
+        add(checkTLS(ctx, target, c.TLSConfig(), timeout))
+    }
+
+    auth, info := checkAuth(ctx, c)
+    add(auth)
+    add(checkServer(info))
+    add(checkWritable(ctx, c))
+
+    return report
+}
+
+// exitCode returns the exit code of the first failed check, or ExitOK.
+func (r doctorReport) exitCode() int {
+    for _, check := range r.Checks {
+        if check.Status == checkFail {
+            if check.err == nil {
+                return ExitError
+            }
+            return exitCode(check.err)
+        }
+    }
+    return ExitOK
+}
+
+// doctorSettings lists every setting with its effective value and source.
+func doctorSettings(cmd *cobra.Command) []doctorSetting {
+    var settings []doctorSetting
+    for _, key := range config.Keys() {
+        var value string
+        switch key {
+        case "password":
+            value = config.Global.Password
+        case "token":
+            value = config.Global.Token
+        case "noProxy":
+            value = strings.Join(viper.GetStringSlice(key), ",")
+        default:
+            value = viper.GetString(key)
+        }
+
+        flag := cmd.Flags().Lookup(kebabCase(key))
+        source := config.Source(key, flag != nil && flag.Changed)
+        if (key == "password" || key == "token") && value != "" && viper.GetString(key) == "" {
+            // filled in by ResolveCredentials
+            if helper := config.Global.CredentialHelper; helper != "" {
+                source = "credential helper " + helper
+            } else {
+                source = config.Global.CredentialStore + " credential store"
+            }
+        }
+        if client.IsSensitive(key) {
+            value = hideSecret(value)
+        }
+        settings = append(settings, doctorSetting{Key: key, Value: value, Source: source})
+    }
+    return settings
+}
+
+func checkConfigFiles() doctorCheck {
+    check := doctorCheck{Name: "Config", Status: checkPass}
+    if len(config.LoadedFiles) == 0 {
+        check.Status = checkWarn
+        check.Detail = "no config file found; using flags, NEXUS_* variables and defaults"
+        return check
+    }
+    var paths, problems []string
+    for _, f := range config.LoadedFiles {
+        paths = append(paths, f.Path)
+        if err := config.Validate(f.Path, f.Data); err != nil {
+            var schemaErr *config.SchemaError
+            if errors.As(err, &schemaErr) {
+                for _, p := range schemaErr.Problems {
+                    problems = append(problems, f.Path+": "+p)
+                }
+            }
+            check.err = err
+        }
+        if info, err := os.Stat(f.Path); err == nil && info.Mode().Perm()&0077 != 0 && fileHasSecrets(f.Data) {
+            problems = append(problems, fmt.Sprintf("%s holds secrets but is readable by others (mode %o)", f.Path, info.Mode().Perm()))
+            if check.Status == checkPass {
+                check.Status = checkWarn
+            }
+        }
+    }
+    if check.err != nil {
+        check.Status = checkFail
+    }
+    if len(problems) > 0 {
+        check.Detail = strings.Join(problems, "; ")
+        return check
+    }
+    check.Detail = "loaded " + strings.Join(paths, ", ")
+    return check
+}
+
+func fileHasSecrets(data map[string]interface{}) bool {
+    for key, value := range data {
+        if section, ok := value.(map[string]interface{}); ok {
+            if fileHasSecrets(section) {
+                return true
+            }
+            continue
+        }
+        if client.IsSensitive(key) && value != "" {
+            return true
+        }
+    }
+    return false
+}
+
+func checkURL(raw string) (*url.URL, doctorCheck) {
+    check := doctorCheck{Name: "URL"}
+    if raw == "" {
+        check.Status = checkFail
+        check.Detail = "no URL configured; run \"nexuscli login <url>\" or \"config set --url\""
+        check.err = usageError{errors.New("no URL configured")}
+        return nil, check
+    }
+    if err := config.CheckValue("url", raw); err != nil {
+        check.Status = checkFail
+        check.Detail = err.Error()
+        check.err = err
+        return nil, check
+    }
+    u, _ := url.Parse(raw)
+    check.Status = checkPass
+    check.Detail = raw
+    return u, check
+}
+
+func checkDNS(ctx context.Context, u *url.URL, timeout time.Duration) doctorCheck {
+    check := doctorCheck{Name: "DNS"}
+    host := u.Hostname()
+    if net.ParseIP(host) != nil {
+        check.Status = checkPass
+        check.Detail = host + " is an IP address"
+        return check
+    }
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+    addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+    if err != nil {
+        check.Status = checkFail
+        check.Detail = err.Error()
+        check.err = err
+        return check
+    }
+    check.Status = checkPass
+    check.Detail = fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", "))
+    return check
+}
+
+func checkTCP(ctx context.Context, u *url.URL, timeout time.Duration) doctorCheck {
+    check := doctorCheck{Name: "TCP"}
+    addr := hostPort(u)
+    start := time.Now()
+    d := net.Dialer{Timeout: timeout}
+    conn, err := d.DialContext(ctx, "tcp", addr)
+    if err != nil {
+        check.Status = checkFail
+        check.Detail = err.Error()
+        check.err = err
+        return check
+    }
+    conn.Close()
+    check.Status = checkPass
+    check.Detail = fmt.Sprintf("connected to %s in %s", addr, time.Since(start).Round(100*time.Microsecond))
+    return check
+}
+
+func checkTLS(ctx context.Context, u *url.URL, cfg *tls.Config, timeout time.Duration) doctorCheck {
+    check := doctorCheck{Name: "TLS"}
+    // a copy, so setting ServerName leaves the caller's config alone
+    if cfg == nil {
+        cfg = &tls.Config{}
+    } else {
+        cfg = cfg.Clone()
+    }
+    cfg.ServerName = u.Hostname()
+
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+    d := tls.Dialer{Config: cfg}
+    conn, err := d.DialContext(ctx, "tcp", hostPort(u))
+    if err != nil {
+        check.Status = checkFail
+        check.Detail = err.Error()
+        check.err = err
+        return check
+    }
+    defer conn.Close()
+    state := conn.(*tls.Conn).ConnectionState()
+
+    var chain []string
+    var expiring []string
+    for _, cert := range state.PeerCertificates {
+        chain = append(chain, fmt.Sprintf("%s (issuer %s, expires %s)",
+            certName(cert), certName(&x509.Certificate{Subject: cert.Issuer}), cert.NotAfter.Format("2006-01-02")))
+        if time.Until(cert.NotAfter) < 30*24*time.Hour {
+            expiring = append(expiring, certName(cert))
+        }
+    }
+    check.Status = checkPass
+    check.Detail = fmt.Sprintf("%s; chain: %s", tls.VersionName(state.Version), strings.Join(chain, " <- "))
+    if cfg.InsecureSkipVerify {
+        check.Status = checkWarn
+        check.Detail = "certificate not verified (insecureSkipVerify); " + check.Detail
+    } else if len(expiring) > 0 {
+        check.Status = checkWarn
+        check.Detail = "expires within 30 days: " + strings.Join(expiring, ", ") + "; " + check.Detail
+    }
+    return check
+}
+
+func certName(cert *x509.Certificate) string {
+    if cert.Subject.CommonName != "" {
+        return cert.Subject.CommonName
+    }
+    if len(cert.DNSNames) > 0 {
+        return cert.DNSNames[0]
+    }
+    return cert.Subject.String()
+}
+
+func checkAuth(ctx context.Context, c *client.NexusClient) (doctorCheck, *client.ServerInfo) {
+    check := doctorCheck{Name: "Authentication"}
+    who := "anonymous"
+    switch {
+    case config.Global.Token != "":
+        who = "token"
+    case config.Global.Username != "" && config.Global.Password != "":
+        who = "user '" + config.Global.Username + "'"
+    }
+
+    info, err := c.CheckStatus(ctx)
+    switch {
+    case err == nil:
+        check.Status = checkPass
+        check.Detail = "authenticated as " + who
+    case client.IsForbidden(err):
+        check.Status = checkWarn
+        check.Detail = who + " is authenticated but may not read the status checks (nexus:metrics:read)"
+        info, _ = c.ServerInfo(ctx)
+    case client.IsUnauthorized(err):
+        check.Status = checkFail
+        check.Detail = "credentials of " + who + " were rejected"
+        check.err = err
+    default:
+        check.Status = checkFail
+        check.Detail = err.Error()
+        check.err = err
+    }
+    if info != nil {
+        for name, sc := range info.Checks {
+            if !sc.Healthy && check.Status == checkPass {
+                check.Status = checkWarn
+                check.Detail += fmt.Sprintf("; status check '%s' is unhealthy: %s", name, sc.Message)
+            }
+        }
+    }
+    return check, info
+}
+
+func checkServer(info *client.ServerInfo) doctorCheck {
+    check := doctorCheck{Name: "Server"}
+    if info == nil {
+        check.Status = checkSkip
+        check.Detail = "server did not answer"
+        return check
+    }
+    if info.Version == "" {
+        check.Status = checkWarn
+        check.Detail = "version and edition unknown (no Server header)"
+        return check
+    }
+    check.Status = checkPass
+    check.Detail = "Nexus " + info.Version
+    if info.Edition != "" {
+        check.Detail += " (" + info.Edition + ")"
+    }
+    return check
+}
+
+func checkWritable(ctx context.Context, c *client.NexusClient) doctorCheck {
+    check := doctorCheck{Name: "Writable"}
+    writable, err := c.Writable(ctx)
+    switch {
+    case err != nil:
+        check.Status = checkFail
+        check.Detail = err.Error()
+        check.err = err
+    case writable:
+        check.Status = checkPass
+        check.Detail = "node accepts writes"
+    default:
+        check.Status = checkFail
+        check.Detail = "node is read-only"
+    }
+    return check
+}
+
+func hostPort(u *url.URL) string {
+    if u.Port() != "" {
+        return u.Host
+    }
+    port := "80"
+    if u.Scheme == "https" {
+        port = "443"
+    }
+    return net.JoinHostPort(u.Hostname(), port)
+}
+
+// kebabCase turns a config key into its flag name: retryMaxWait becomes
+// retry-max-wait.
+func kebabCase(key string) string {
+    var b strings.Builder
+    for i, r := range key {
+        if r >= 'A' && r <= 'Z' {
+            if i > 0 {
+                b.WriteByte('-')
+            }
+            r += 'a' - 'A'
+        }
+        b.WriteRune(r)
+    }
+    return b.String()
+}
+
+func printDoctorReport(report doctorReport) {
+    switch strings.ToLower(outputFormat) {
+    case "json":
+        data, _ := json.MarshalIndent(report, "", "  ")
+        fmt.Println(string(data))
+        return
+    case "yaml", "yml":
+        data, _ := yaml.Marshal(report)
+        fmt.Println(string(data))
+        return
+    }
+
+    if report.Context != "" {
+        fmt.Printf("Context: %s\n\n", report.Context)
+    }
+    settings := []map[string]interface{}{}
+    for _, s := range report.Settings {
+        settings = append(settings, map[string]interface{}{"SETTING": s.Key, "VALUE": s.Value, "SOURCE": s.Source})
+    }
+    output.Render(settings, "table", []string{"SETTING", "VALUE", "SOURCE"}, nil)
+    fmt.Println()
+
+    checks := []map[string]interface{}{}
+    for _, c := range report.Checks {
+        checks = append(checks, map[string]interface{}{
+            "STATUS": "[" + strings.ToUpper(c.Status) + "]",
+            "CHECK":  c.Name,
+            "DETAIL": c.Detail,
+            "status": c.Status,
+        })
+    }
+    output.Render(checks, outputFormat, []string{"STATUS", "CHECK", "DETAIL"}, func(c map[string]interface{}) {
+        color := map[string]string{checkPass: "32", checkWarn: "33", checkFail: "31", checkSkip: "90"}[c["status"].(string)]
+        fmt.Printf("\033[%sm%-6s\033[0m %-15s %s\n", color, c["STATUS"], c["CHECK"], c["DETAIL"])
+    })
+}
+
+func init() {
+    configCmd.AddCommand(configDoctorCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "path/filepath"
+    "testing"
+)
+
+func TestDoctorClientCheck(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Server", "Nexus/3.70.1-02 (OSS)")
+        w.Write([]byte(`{}`))
+    }))
+    defer srv.Close()
+
+    tests := []struct {
+        name       string
+        env        map[string]string
+        wantClient string
+        wantCode   int
+    }{
+        {
+            name:     "working settings",
+            env:      map[string]string{"NEXUS_URL": srv.URL},
+            wantCode: ExitOK,
+        },
+        {
+            name: "missing CA bundle",
+            env: map[string]string{
+                "NEXUS_URL":    "https://nexus.example.com",
+                "NEXUS_CACERT": filepath.Join(t.TempDir(), "missing.pem"),
+            },
+            wantClient: checkFail,
+            wantCode:   ExitUsage,
+        },
+        {
+            name:       "proxy without host",
+            env:        map[string]string{"NEXUS_URL": srv.URL, "NEXUS_PROXY": "http://"},
+            wantClient: checkFail,
+            wantCode:   ExitUsage,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            isolateConfig(t)
+            for name, value := range tt.env {
+                t.Setenv(name, value)
+            }
+            if err := rootCmd.PersistentPreRunE(configDoctorCmd, nil); err != nil {
+                t.Fatalf("doctor did not start: %v", err)
+            }
+            configDoctorCmd.SetContext(context.Background())
+
+            report := runDoctor(configDoctorCmd)
+            statuses := map[string]string{}
+            for _, check := range report.Checks {
+                statuses[check.Name] = check.Status
+            }
+            if statuses["Client"] != tt.wantClient {
+                t.Errorf("Client check %q, want %q", statuses["Client"], tt.wantClient)
+            }
+            if tt.wantClient == checkFail && statuses["Authentication"] != checkSkip {
+                t.Errorf("Authentication check %q after a broken client, want %q", statuses["Authentication"], checkSkip)
+            }
+            if got := report.exitCode(); got != tt.wantCode {
+                t.Errorf("exit code %d, want %d; checks %v", got, tt.wantCode, report.Checks)
+            }
+        })
+    }
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "net/url"
+    "os"
+    "nexuscli/config"
+    "nexuscli/internal/client"
+)
+
+// Exit codes returned by nexuscli. They are part of the CLI contract so
+// scripts can react to the class of failure; do not renumber them.
+const (
+    ExitOK           = 0
+    ExitError        = 1   // unclassified failure
+    ExitUsage        = 2   // invalid arguments, flags or config file
+    ExitUnauthorized = 3   // 401/403: bad credentials or missing privileges
+    ExitNotFound     = 4   // 404: the object does not exist
+    ExitConflict     = 5   // the object already exists
+    ExitInvalid      = 6   // 400: Nexus rejected the request
+    ExitServer       = 7   // 5xx from Nexus
+    ExitUnreachable  = 8   // connection refused, DNS failure, timeout
+    ExitInterrupted  = 130 // cancelled by SIGINT/SIGTERM
+)
+
+// usageError marks errors caused by how the CLI was invoked.
+type usageError struct {
+    error
+}
+
+// notFoundError marks objects the CLI found missing itself, e.g. in a
+// repository list, rather than from a 404. It exits with ExitNotFound too.
+type notFoundError struct {
+    error
+}
+
+// fail reports err and exits with the matching exit code. A dry run is not
+// a failure: the curl commands have already been printed.
+func fail(err error, format string, a ...interface{}) {
+    if errors.Is(err, client.ErrDryRun) {
+        os.Exit(ExitOK)
+    }
+    fmt.Fprintf(os.Stderr, format+": %v\n", append(a, err)...)
+    os.Exit(exitCode(err))
+}
+
+// exitCode maps an error to one of the documented exit codes.
+func exitCode(err error) int {
+    switch {
+    case err == nil:
+        return ExitOK
+    case errors.As(err, new(usageError)), errors.As(err, new(*config.SchemaError)):
+        return ExitUsage
+    case errors.Is(err, context.Canceled):
+        return ExitInterrupted
+    case client.IsUnauthorized(err), client.IsForbidden(err):
+        return ExitUnauthorized
+    case errors.As(err, new(notFoundError)), client.IsNotFound(err):
+        return ExitNotFound
+    case client.IsConflict(err):
+        return ExitConflict
+    case client.IsValidation(err):
+        return ExitInvalid
+    case client.IsServerError(err):
+        return ExitServer
+    }
+
+    var urlErr *url.Error
+    var opErr *net.OpError
+    var dnsErr *net.DNSError
+    if errors.As(err, &urlErr) || errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+        return ExitUnreachable
+    }
+    return ExitError
+}
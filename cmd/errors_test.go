@@ -0,0 +1,60 @@
+package cmd
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "net/url"
+    "testing"
+    "nexuscli/config"
+    "nexuscli/internal/client"
+    "github.com/spf13/cobra"
+)
+
+func TestExitCode(t *testing.T) {
+    tests := []struct {
+        name string
+        err  error
+        want int
+    }{
+        {"nil", nil, ExitOK},
+        {"usage", usageError{errors.New("accepts 1 arg(s), received 0")}, ExitUsage},
+        {"config schema", fmt.Errorf("load: %w", &config.SchemaError{Path: "c.yaml"}), ExitUsage},
+        {"cancelled", fmt.Errorf("list: %w", context.Canceled), ExitInterrupted},
+        {"401", &client.APIError{StatusCode: 401}, ExitUnauthorized},
+        {"403", &client.APIError{StatusCode: 403}, ExitUnauthorized},
+        {"404", &client.APIError{StatusCode: 404}, ExitNotFound},
+        {"not in the repository list", notFoundError{errors.New("repository 'x' does not exist")}, ExitNotFound},
+        {"409", &client.APIError{StatusCode: 409}, ExitConflict},
+        {"400 already exists", &client.APIError{StatusCode: 400, Message: "already exists"}, ExitConflict},
+        {"400", &client.APIError{StatusCode: 400}, ExitInvalid},
+        {"502", &client.APIError{StatusCode: 502}, ExitServer},
+        {"url error", &url.Error{Op: "Get", URL: "https://nexus", Err: errors.New("timeout")}, ExitUnreachable},
+        {"dns error", &net.DNSError{Name: "nexus", Err: "no such host"}, ExitUnreachable},
+        {"other", errors.New("boom"), ExitError},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := exitCode(tt.err); got != tt.want {
+                t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestUsageArgs(t *testing.T) {
+    parent := &cobra.Command{Use: "repo"}
+    child := &cobra.Command{Use: "get", Args: cobra.ExactArgs(1)}
+    parent.AddCommand(child)
+    usageArgs(parent)
+
+    if err := child.Args(child, []string{"a"}); err != nil {
+        t.Errorf("one argument: %v", err)
+    }
+    err := child.Args(child, nil)
+    if got := exitCode(err); got != ExitUsage {
+        t.Errorf("missing argument exits with %d, want %d", got, ExitUsage)
+    }
+}
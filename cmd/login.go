@@ -0,0 +1,233 @@
+package cmd
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "nexuscli/config"
+    "nexuscli/internal/client"
+    "nexuscli/internal/credentials"
+    "nexuscli/internal/terminal"
+    "github.com/spf13/cobra"
+    "github.com/spf13/viper"
+)
+
+var (
+    loginUsername      string
+    loginPasswordStdin bool
+)
+
+var loginCmd = &cobra.Command{
+    Use:   "login [url]",
+    Short: "Log in to a Nexus server and save the credentials",
+    Long: `Log in to a Nexus server. The username and password are prompted for
+(the password without echo) unless given with --username and
+--password-stdin. They are checked against the system status endpoint
+before anything is saved.
+
+On success the URL, username, server version and edition are written to
+the active context (or the one named with --context) and the password to
+its credential store or credential helper. Any saved token is removed.`,
+    Example: `  nexuscli login https://nexus.example.com
+  echo "$NEXUS_PASSWORD" | nexuscli login https://nexus.example.com -u ci --password-stdin`,
+    Args: cobra.MaximumNArgs(1),
+    Run: func(cmd *cobra.Command, args []string) {
+        in := stdin
+
+        url := viper.GetString("url")
+        if len(args) == 1 {
+            url = args[0]
+        }
+        if url == "" && !loginPasswordStdin {
+            u, err := terminal.Prompt(in, "Nexus URL: ")
+            if err != nil {
+                fail(err, "Error reading URL")
+            }
+            url = strings.TrimSpace(u)
+        }
+        url = strings.TrimSuffix(url, "/")
+        if url == "" {
+            fmt.Fprintln(os.Stderr, "Error: no Nexus URL given.")
+            os.Exit(ExitUsage)
+        }
+        if err := config.CheckValue("url", url); err != nil {
+            fail(err, "Error")
+        }
+
+        username := loginUsername
+        if username == "" && !loginPasswordStdin {
+            def := viper.GetString("username")
+            prompt := "Username: "
+            if def != "" {
+                prompt = fmt.Sprintf("Username [%s]: ", def)
+            }
+            u, err := terminal.Prompt(in, prompt)
+            if err != nil {
+                fail(err, "Error reading username")
+            }
+            username = strings.TrimSpace(u)
+            if username == "" {
+                username = def
+            }
+        }
+        if username == "" {
+            fmt.Fprintln(os.Stderr, "Error: no username given; use --username with --password-stdin.")
+            os.Exit(ExitUsage)
+        }
+
+        var password string
+        if loginPasswordStdin {
+            data, err := io.ReadAll(in)
+            if err != nil {
+                fail(err, "Error reading password from stdin")
+            }
+            password = strings.TrimRight(string(data), "\r\n")
+        } else {
+            p, err := terminal.ReadPassword("Password: ")
+            if errors.Is(err, terminal.ErrNotTerminal) {
+                fmt.Fprintln(os.Stderr, "Error: cannot prompt for a password without a terminal; use --password-stdin.")
+                os.Exit(ExitUsage)
+            }
+            if err != nil {
+                fail(err, "Error reading password")
+            }
+            password = p
+        }
+        if password == "" {
+            fmt.Fprintln(os.Stderr, "Error: empty password.")
+            os.Exit(ExitUsage)
+        }
+
+        cfg := config.Global
+        cfg.URL = url
+        cfg.Username = username
+        cfg.Password = password
+        cfg.Token = ""
+        c, err := newClient(cfg)
+        if err != nil {
+            fail(err, "Error")
+        }
+
+        info, err := c.CheckStatus(cmd.Context())
+        if client.IsForbidden(err) {
+            // valid credentials, but the user may not read the status checks
+            info, err = c.ServerInfo(cmd.Context())
+        }
+        if client.IsUnauthorized(err) {
+            fmt.Fprintf(os.Stderr, "Login failed: invalid username or password for %s.\n", url)
+            os.Exit(ExitUnauthorized)
+        }
+        if err != nil {
+            fail(err, "Login failed")
+        }
+
+        f, err := config.LoadFile()
+        if err != nil {
+            fail(err, "Error loading config")
+        }
+        target := config.ActiveContext
+        f.Set(target, "url", url)
+        f.Set(target, "username", username)
+        f.Set(target, "serverVersion", info.Version)
+        f.Set(target, "serverEdition", info.Edition)
+
+        // a token takes precedence over the password, so a stale one must go
+        if _, err := eraseSecret(f, target, "token"); err != nil {
+            fail(err, "Error removing old token")
+        }
+        f.Delete(target, "token")
+
+        if helper := viper.GetString("credentialHelper"); helper != "" {
+            err := credentials.Helper{Name: helper}.Store(credentials.HelperCredentials{
+                ServerURL: url,
+                Username:  username,
+                Secret:    password,
+            })
+            if err != nil {
+                fail(err, "Error storing password")
+            }
+            f.Delete(target, "password")
+        } else if err := storeSecret(f, target, "password", password); err != nil {
+            fail(err, "Error storing password")
+        }
+
+        if err := f.Save(); err != nil {
+            fail(err, "Error saving config")
+        }
+
+        server := "Nexus"
+        if info.Version != "" {
+            server += " " + info.Version
+        }
+        if info.Edition != "" {
+            server += " (" + info.Edition + ")"
+        }
+        fmt.Printf("Logged in to %s at %s as '%s'.\n", server, url, username)
+        for name, check := range info.Checks {
+            if !check.Healthy {
+                fmt.Fprintf(os.Stderr, "Warning: status check '%s' is unhealthy: %s\n", name, check.Message)
+            }
+        }
+    },
+}
+
+var logoutCmd = &cobra.Command{
+    Use:   "logout",
+    Short: "Remove the saved password and token",
+    Long: `Remove the password and token of the active context (or the one named
+with --context) from the config file, its credential store and its
+credential helper. The URL and username are kept.`,
+    Args: cobra.NoArgs,
+    Run: func(cmd *cobra.Command, args []string) {
+        f, err := config.LoadFile()
+        if err != nil {
+            fail(err, "Error loading config")
+        }
+        target := config.ActiveContext
+
+        removed := false
+        section := f.Section(target, false)
+        for _, kind := range []string{"password", "token"} {
+            if _, ok := section[kind]; ok {
+                removed = true
+                f.Delete(target, kind)
+            }
+            erased, err := eraseSecret(f, target, kind)
+            if err != nil {
+                fail(err, "Error removing %s", kind)
+            }
+            removed = removed || erased
+        }
+
+        url := viper.GetString("url")
+        if helper := viper.GetString("credentialHelper"); helper != "" && url != "" {
+            if err := (credentials.Helper{Name: helper}).Erase(url); err != nil {
+                fail(err, "Error erasing credentials")
+            }
+            removed = true
+        }
+
+        if !removed {
+            fmt.Println("Not logged in.")
+            return
+        }
+        if err := f.Save(); err != nil {
+            fail(err, "Error saving config")
+        }
+        if url != "" {
+            fmt.Printf("Logged out of %s.\n", url)
+            return
+        }
+        fmt.Println("Logged out.")
+    },
+}
+
+func init() {
+    rootCmd.AddCommand(loginCmd)
+    rootCmd.AddCommand(logoutCmd)
+
+    loginCmd.Flags().StringVarP(&loginUsername, "username", "u", "", "Nexus username")
+    loginCmd.Flags().BoolVar(&loginPasswordStdin, "password-stdin", false, "Read the password from stdin")
+}
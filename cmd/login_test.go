@@ -0,0 +1,113 @@
+package cmd
+
+import (
+    "bufio"
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "nexuscli/config"
+)
+
+// nexusStatus serves the status endpoints of a Nexus 3.70.1 OSS server that
+// accepts ci/secret. statusCheck is the answer to the status check with
+// valid credentials, e.g. 403 for a user without the privilege.
+func nexusStatus(t *testing.T, statusCheck int) *httptest.Server {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Server", "Nexus/3.70.1-02 (OSS)")
+        username, password, _ := r.BasicAuth()
+        switch {
+        case r.URL.Path == "/service/rest/v1/status":
+        case username != "ci" || password != "secret":
+            w.WriteHeader(http.StatusUnauthorized)
+        case r.URL.Path == "/service/rest/v1/status/check" && statusCheck != http.StatusOK:
+            w.WriteHeader(statusCheck)
+        case r.URL.Path == "/service/rest/v1/status/check":
+            w.Write([]byte(`{"Blob Stores Ready": {"healthy": true}}`))
+        default:
+            http.NotFound(w, r)
+        }
+    }))
+    t.Cleanup(srv.Close)
+    return srv
+}
+
+// runLogin runs login as "login url -u ci --password-stdin" with password
+// on stdin.
+func runLogin(t *testing.T, url, password string) {
+    t.Helper()
+    if err := rootCmd.PersistentPreRunE(loginCmd, nil); err != nil {
+        t.Fatal(err)
+    }
+    loginUsername, loginPasswordStdin = "ci", true
+    stdin = bufio.NewReader(strings.NewReader(password + "\n"))
+    t.Cleanup(func() { loginUsername, loginPasswordStdin = "", false })
+    loginCmd.SetContext(context.Background())
+    loginCmd.Run(loginCmd, []string{url})
+}
+
+func loadSection(t *testing.T) map[string]interface{} {
+    t.Helper()
+    f, err := config.LoadFile()
+    if err != nil {
+        t.Fatal(err)
+    }
+    return f.Section(config.ActiveContext, false)
+}
+
+func TestLogin(t *testing.T) {
+    tests := []struct {
+        name        string
+        statusCheck int
+    }{
+        {"status check", http.StatusOK},
+        {"status check forbidden", http.StatusForbidden},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            isolateConfig(t)
+            t.Setenv("NEXUS_CREDENTIALSTORE", "plain")
+            srv := nexusStatus(t, tt.statusCheck)
+
+            runLogin(t, srv.URL+"/", "secret")
+
+            section := loadSection(t)
+            want := map[string]interface{}{
+                "url":           srv.URL,
+                "username":      "ci",
+                "password":      "secret",
+                "serverVersion": "3.70.1-02",
+                "serverEdition": "OSS",
+            }
+            for key, value := range want {
+                if section[key] != value {
+                    t.Errorf("%s = %v, want %v", key, section[key], value)
+                }
+            }
+        })
+    }
+}
+
+func TestLogout(t *testing.T) {
+    isolateConfig(t)
+    t.Setenv("NEXUS_CREDENTIALSTORE", "plain")
+    srv := nexusStatus(t, http.StatusOK)
+    runLogin(t, srv.URL, "secret")
+
+    if err := rootCmd.PersistentPreRunE(logoutCmd, nil); err != nil {
+        t.Fatal(err)
+    }
+    logoutCmd.Run(logoutCmd, nil)
+
+    section := loadSection(t)
+    for _, key := range []string{"password", "token"} {
+        if _, ok := section[key]; ok {
+            t.Errorf("%s is still saved", key)
+        }
+    }
+    if section["url"] != srv.URL || section["username"] != "ci" {
+        t.Errorf("logout removed the URL or username: %v", section)
+    }
+}
@@ -1,10 +1,16 @@
 package cmd
 
 import (
+    "encoding/json"
     "fmt"
     "os"
+    "sort"
+    "strconv"
+    "strings"
+    "nexuscli/internal/client"
     "nexuscli/internal/output"
     "github.com/spf13/cobra"
+    "gopkg.in/yaml.v3"
 )
 
 var repoCmd = &cobra.Command{
@@ -13,22 +19,145 @@ var repoCmd = &cobra.Command{
     Long:  `Allows creating, deleting, and listing Nexus repositories.`,
 }
 
+var (
+    repoWritePolicy  string
+    repoProprietary  bool
+    aptKeypairFile   string
+    aptPassphrase    string
+    aptPassStdin     bool
+    repoReveal       bool
+    yumRepodataDepth int
+    yumDeployPolicy  string
+)
+
+// hostedFormats are the formats Nexus can host.
+var hostedFormats = []string{
+    "apt", "cargo", "conan", "docker", "gitlfs", "helm", "maven2", "npm",
+    "nuget", "pypi", "r", "raw", "rubygems", "yum",
+}
+
 var repoCreateCmd = &cobra.Command{
     Use:   "create <repo_type> <repo_name>",
     Short: "Create a new Nexus repository",
-    Args:  cobra.ExactArgs(2),
+    Long: `Create a hosted repository. repo_type is its format: ` + strings.Join(hostedFormats, ", ") + `
+("maven" is accepted for maven2). Flags starting with a format name only
+apply to that format; apt repositories need --apt-distribution and
+--apt-keypair-file. Use create-proxy and create-group for the other types.`,
+    Example: `  nexuscli repo create maven maven-releases --maven-version-policy RELEASE --write-policy ALLOW_ONCE
+  nexuscli repo create docker docker-hosted --docker-http-port 8082 --write-policy ALLOW
+  nexuscli repo create apt apt-hosted --apt-distribution bookworm --apt-keypair-file signing.asc
+  nexuscli repo create yum yum-hosted --yum-repodata-depth 1`,
+    Args: cobra.ExactArgs(2),
     Run: func(cmd *cobra.Command, args []string) {
-        repoType := args[0]
+        repoType := normalizeFormat(args[0])
         repoName := args[1]
 
-        if err := nexusClient.CreateRepository(repoType, repoName); err != nil {
-            fmt.Printf("Error creating repository '%s': %v\n", repoName, err)
-            os.Exit(1)
+        if err := readStdinSecrets(cmd); err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+        repo, err := buildHostedRepository(cmd, repoType, repoName)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+
+        if err := nexusClient.CreateHostedRepository(cmd.Context(), *repo); err != nil {
+            fail(err, "Error creating repository '%s'", repoName)
         }
         fmt.Printf("Repository '%s' of type '%s' created successfully.\n", repoName, repoType)
     },
 }
 
+func buildHostedRepository(cmd *cobra.Command, format, name string) (*client.Repository, error) {
+    if !contains(hostedFormats, format) {
+        if contains(proxyFormats, format) {
+            return nil, fmt.Errorf("Nexus cannot host %s repositories; use \"repo create-proxy %s\"", format, format)
+        }
+        return nil, fmt.Errorf("unsupported repository type '%s' (use %s)", format, strings.Join(hostedFormats, ", "))
+    }
+    if err := checkFormatFlags(cmd, format); err != nil {
+        return nil, err
+    }
+    writePolicy := strings.ToUpper(repoWritePolicy)
+    if err := checkEnum("--write-policy", writePolicy, "ALLOW", "ALLOW_ONCE", "DENY"); err != nil {
+        return nil, err
+    }
+    if repoBlobStore == "" {
+        return nil, fmt.Errorf("--blob-store must not be empty")
+    }
+
+    repo := &client.Repository{
+        Name:   name,
+        Format: format,
+        Online: &repoOnline,
+        Storage: &client.Storage{
+            BlobStoreName:               repoBlobStore,
+            StrictContentTypeValidation: repoStrictContent,
+            WritePolicy:                 writePolicy,
+        },
+    }
+    if len(repoCleanupPolicies) > 0 {
+        repo.Cleanup = &client.Cleanup{PolicyNames: repoCleanupPolicies}
+    }
+    if cmd.Flags().Changed("proprietary-components") {
+        repo.Component = &client.ComponentConfig{ProprietaryComponents: repoProprietary}
+    }
+
+    switch format {
+    case "maven2":
+        maven, err := mavenConfig("STRICT")
+        if err != nil {
+            return nil, err
+        }
+        if maven.VersionPolicy == "SNAPSHOT" && writePolicy == "ALLOW_ONCE" && !cmd.Flags().Changed("write-policy") {
+            // snapshots are redeployed by design
+            repo.Storage.WritePolicy = "ALLOW"
+        }
+        repo.Maven = maven
+    case "docker":
+        docker, err := dockerConfig(cmd)
+        if err != nil {
+            return nil, err
+        }
+        repo.Docker = docker
+    case "apt":
+        if aptDistribution == "" {
+            return nil, fmt.Errorf("apt repositories need --apt-distribution, e.g. bookworm")
+        }
+        if aptKeypairFile == "" {
+            return nil, fmt.Errorf("hosted apt repositories sign their metadata and need --apt-keypair-file with an armored PGP private key")
+        }
+        keypair, err := readAptKeypair()
+        if err != nil {
+            return nil, err
+        }
+        repo.Apt = &client.AptConfig{Distribution: aptDistribution}
+        repo.AptSigning = &client.AptSigning{Keypair: keypair, Passphrase: aptPassphrase}
+    case "yum":
+        if yumRepodataDepth < 0 || yumRepodataDepth > 5 {
+            return nil, fmt.Errorf("--yum-repodata-depth must be between 0 and 5, got %d", yumRepodataDepth)
+        }
+        deployPolicy := strings.ToUpper(yumDeployPolicy)
+        if err := checkEnum("--yum-deploy-policy", deployPolicy, "STRICT", "PERMISSIVE"); err != nil {
+            return nil, err
+        }
+        repo.Yum = &client.YumConfig{RepodataDepth: yumRepodataDepth, DeployPolicy: deployPolicy}
+    }
+    return repo, nil
+}
+
+func readAptKeypair() (string, error) {
+    keypair, err := os.ReadFile(aptKeypairFile)
+    if err != nil {
+        return "", fmt.Errorf("could not read --apt-keypair-file: %v", err)
+    }
+    if !strings.Contains(string(keypair), "BEGIN PGP PRIVATE KEY BLOCK") {
+        return "", fmt.Errorf("%s is not an armored PGP private key (gpg --export-secret-keys --armor)", aptKeypairFile)
+    }
+    return string(keypair), nil
+}
+
 var repoDeleteCmd = &cobra.Command{
     Use:   "delete <repo_name>",
     Short: "Delete a Nexus repository",
@@ -36,51 +165,196 @@ var repoDeleteCmd = &cobra.Command{
     Run: func(cmd *cobra.Command, args []string) {
         repoName := args[0]
 
-        if err := nexusClient.DeleteRepository(repoName); err != nil {
-            fmt.Printf("Error deleting repository '%s': %v\n", repoName, err)
-            os.Exit(1)
+        if err := nexusClient.DeleteRepository(cmd.Context(), repoName); err != nil {
+            fail(err, "Error deleting repository '%s'", repoName)
         }
         fmt.Printf("Repository '%s' deleted successfully.\n", repoName)
     },
 }
 
-var repoListCmd = &cobra.Command{
-    Use:   "list",
-    Short: "List all Nexus repositories",
+var repoGetCmd = &cobra.Command{
+    Use:   "get <repo_name>",
+    Short: "Show the full configuration of a repository",
+    Long: `Show every setting of a repository: storage, cleanup, proxy remote,
+caching, HTTP client, group members and the settings of its format.
+
+With -o json or -o yaml the output holds every setting Nexus returns, keys
+sorted, so it can be diffed against another repository or kept as a
+backup. The table lists one setting per line. Secrets such as the apt
+signing key are masked in every format unless --reveal is given.`,
+    Example: `  nexuscli repo get maven-central
+  nexuscli repo get maven-releases -o yaml > maven-releases.yaml
+  diff <(nexuscli repo get npm-a -o json) <(nexuscli repo get npm-b -o json)`,
+    Args: cobra.ExactArgs(1),
     Run: func(cmd *cobra.Command, args []string) {
-        repos, err := nexusClient.ListRepositories()
+        repoName := args[0]
+
+        repo, err := nexusClient.GetRepository(cmd.Context(), repoName)
         if err != nil {
-            fmt.Printf("Error listing repositories: %v\n", err)
-            os.Exit(1)
+            fail(err, "Error reading repository '%s'", repoName)
+        }
+        doc, err := repositoryDocument(repo)
+        if err != nil {
+            fail(err, "Error reading repository '%s'", repoName)
+        }
+
+        if !repoReveal {
+            maskDocument(doc)
         }
 
-        if len(repos) == 0 {
-            fmt.Println("No repositories found.")
+        switch strings.ToLower(outputFormat) {
+        case "json":
+            data, _ := json.MarshalIndent(doc, "", "  ")
+            fmt.Println(string(data))
+            return
+        case "yaml", "yml":
+            data, _ := yaml.Marshal(doc)
+            fmt.Print(string(data))
             return
         }
 
         items := []map[string]interface{}{}
-        for _, repo := range repos {
-            items = append(items, map[string]interface{}{
-                "NAME":   repo["name"],
-                "FORMAT": repo["format"],
-                "TYPE":   repo["type"],
-                "URL":    repo["url"],
-                "ONLINE": repo["online"],
-            })
-        }
-
-        headers := []string{"NAME", "FORMAT", "TYPE", "URL", "ONLINE"}
-        output.Render(items, outputFormat, headers, func(r map[string]interface{}) {
-            fmt.Printf("\033[32m%s\033[0m\t%s\t%s\t%s\t%v\n",
-                r["NAME"], r["FORMAT"], r["TYPE"], r["URL"], r["ONLINE"])
+        for _, s := range flattenSettings("", doc) {
+            items = append(items, map[string]interface{}{"SETTING": s[0], "VALUE": s[1]})
+        }
+        output.Render(items, outputFormat, []string{"SETTING", "VALUE"}, func(r map[string]interface{}) {
+            fmt.Printf("\033[32m%s\033[0m\t%s\n", r["SETTING"], r["VALUE"])
         })
     },
 }
 
+// repositoryDocument turns repo into plain maps and slices, keeping the
+// settings the model does not know, so it encodes with sorted keys.
+func repositoryDocument(repo *client.Repository) (map[string]interface{}, error) {
+    data, err := json.Marshal(repo)
+    if err != nil {
+        return nil, err
+    }
+    var doc map[string]interface{}
+    if err := json.Unmarshal(data, &doc); err != nil {
+        return nil, err
+    }
+    return doc, nil
+}
+
+// hideSecret replaces a non-empty secret with a fixed placeholder. Unlike
+// mask it shows none of it: a short passphrase would be all but given away.
+func hideSecret(s string) string {
+    if s == "" {
+        return ""
+    }
+    return "****"
+}
+
+// maskSetting hides the value of the dotted setting name if it is a secret.
+func maskSetting(name, value string) string {
+    if client.IsSensitive(name[strings.LastIndex(name, ".")+1:]) {
+        return hideSecret(value)
+    }
+    return value
+}
+
+// maskDocument hides the secrets of a repository document in place.
+func maskDocument(doc interface{}) {
+    switch d := doc.(type) {
+    case map[string]interface{}:
+        for k, v := range d {
+            if s, ok := v.(string); ok && client.IsSensitive(k) {
+                d[k] = hideSecret(s)
+                continue
+            }
+            maskDocument(v)
+        }
+    case []interface{}:
+        for _, v := range d {
+            maskDocument(v)
+        }
+    }
+}
+
+// flattenSettings lists the settings of doc as dotted names and values,
+// identity first, then the rest alphabetically. Lists of plain values are
+// joined in their order, which matters for group members.
+func flattenSettings(prefix string, doc map[string]interface{}) [][2]string {
+    keys := make([]string, 0, len(doc))
+    for k := range doc {
+        keys = append(keys, k)
+    }
+    first := map[string]int{"name": 1, "format": 2, "type": 3, "url": 4, "online": 5}
+    sort.Slice(keys, func(i, j int) bool {
+        a, b := first[keys[i]], first[keys[j]]
+        if prefix != "" || a == b {
+            return keys[i] < keys[j]
+        }
+        if a == 0 || b == 0 {
+            return b == 0
+        }
+        return a < b
+    })
+
+    rows := [][2]string{}
+    for _, k := range keys {
+        name := k
+        if prefix != "" {
+            name = prefix + "." + k
+        }
+        switch v := doc[k].(type) {
+        case map[string]interface{}:
+            if len(v) == 0 {
+                rows = append(rows, [2]string{name, ""})
+            }
+            rows = append(rows, flattenSettings(name, v)...)
+        case []interface{}:
+            parts := []string{}
+            for _, item := range v {
+                if _, ok := item.(map[string]interface{}); ok {
+                    data, _ := json.Marshal(item)
+                    parts = append(parts, string(data))
+                    continue
+                }
+                parts = append(parts, fmt.Sprint(item))
+            }
+            rows = append(rows, [2]string{name, strings.Join(parts, ", ")})
+        case nil:
+            rows = append(rows, [2]string{name, ""})
+        case float64:
+            rows = append(rows, [2]string{name, strconv.FormatFloat(v, 'f', -1, 64)})
+        default:
+            rows = append(rows, [2]string{name, fmt.Sprint(v)})
+        }
+    }
+    return rows
+}
+
 func init() {
     rootCmd.AddCommand(repoCmd)
     repoCmd.AddCommand(repoCreateCmd)
     repoCmd.AddCommand(repoDeleteCmd)
-    repoCmd.AddCommand(repoListCmd)
+    repoCmd.AddCommand(repoGetCmd)
+    repoGetCmd.Flags().BoolVar(&repoReveal, "reveal", false, "Print secrets in plain text")
+
+    addStorageFlags(repoCreateCmd)
+    addFormatFlags(repoCreateCmd)
+    repoCreateCmd.Flags().StringSliceVar(&repoCleanupPolicies, "cleanup-policy", nil, "Cleanup policies to apply")
+    addHostedFlags(repoCreateCmd)
+}
+
+// addHostedFlags adds the settings only hosted repositories have.
+func addHostedFlags(cmd *cobra.Command) {
+    f := cmd.Flags()
+    f.StringVar(&repoWritePolicy, "write-policy", "ALLOW_ONCE", "ALLOW, ALLOW_ONCE (no redeploy) or DENY (read-only)")
+    f.BoolVar(&repoProprietary, "proprietary-components", false, "Mark components as proprietary, protecting them from namespace confusion (Pro)")
+    f.StringVar(&aptKeypairFile, "apt-keypair-file", "", "Armored PGP private key used to sign the metadata")
+    f.StringVar(&aptPassphrase, "apt-passphrase", "", "Passphrase of the signing key (visible to other users in ps; prefer --apt-passphrase-stdin)")
+    f.BoolVar(&aptPassStdin, "apt-passphrase-stdin", false, "Read the passphrase of the signing key from stdin")
+    f.IntVar(&yumRepodataDepth, "yum-repodata-depth", 0, "Directory depth at which repodata is generated (0-5)")
+    f.StringVar(&yumDeployPolicy, "yum-deploy-policy", "STRICT", "STRICT or PERMISSIVE")
+}
+
+// optBool renders a setting Nexus may leave out as an empty cell.
+func optBool(b *bool) interface{} {
+    if b == nil {
+        return nil
+    }
+    return *b
 }
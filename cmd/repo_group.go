@@ -0,0 +1,372 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "strings"
+    "nexuscli/internal/client"
+    "github.com/spf13/cobra"
+)
+
+var (
+    groupMembers        []string
+    groupWritableMember string
+    groupPosition       int
+    groupBefore         string
+    groupAfter          string
+)
+
+// groupFormats are the formats Nexus can group.
+var groupFormats = []string{
+    "cargo", "docker", "go", "maven2", "npm", "nuget", "pypi", "r", "raw", "rubygems", "yum",
+}
+
+var repoCreateGroupCmd = &cobra.Command{
+    Use:   "create-group <format> <repo_name>",
+    Short: "Create a group repository",
+    Long: `Create a repository that serves the content of its members through one URL.
+Members are searched in the order given with --members: the first member
+holding a component wins, so list hosted repositories before proxies.
+
+Formats: ` + strings.Join(groupFormats, ", ") + ` ("maven" is accepted for maven2).`,
+    Example: `  nexuscli repo create-group maven maven-public --members maven-releases,maven-snapshots,maven-central
+  nexuscli repo create-group docker docker-all --members docker-hosted,docker-hub --docker-http-port 8083`,
+    Args: cobra.ExactArgs(2),
+    Run: func(cmd *cobra.Command, args []string) {
+        format := normalizeFormat(args[0])
+        repoName := args[1]
+
+        if !contains(groupFormats, format) {
+            fmt.Fprintf(os.Stderr, "Error: unsupported group format '%s' (use %s)\n", format, strings.Join(groupFormats, ", "))
+            os.Exit(ExitUsage)
+        }
+        if err := checkFormatFlags(cmd, format); err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+        members, err := uniqueMembers(groupMembers)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+        if groupWritableMember != "" && !contains(members, groupWritableMember) {
+            fmt.Fprintf(os.Stderr, "Error: writable member '%s' is not in --members.\n", groupWritableMember)
+            os.Exit(ExitUsage)
+        }
+        if err := checkMembers(cmd.Context(), format, repoName, members); err != nil {
+            fail(err, "Error creating group repository '%s'", repoName)
+        }
+
+        repo := client.Repository{
+            Name:   repoName,
+            Format: format,
+            Online: &repoOnline,
+            Storage: &client.Storage{
+                BlobStoreName:               repoBlobStore,
+                StrictContentTypeValidation: repoStrictContent,
+            },
+            Group: &client.Group{
+                MemberNames:    members,
+                WritableMember: groupWritableMember,
+            },
+        }
+        if format == "docker" {
+            docker, err := dockerConfig(cmd)
+            if err != nil {
+                fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+                os.Exit(ExitUsage)
+            }
+            repo.Docker = docker
+        }
+
+        if err := nexusClient.CreateGroupRepository(cmd.Context(), repo); err != nil {
+            fail(err, "Error creating group repository '%s'", repoName)
+        }
+        fmt.Printf("Group repository '%s' of format '%s' created successfully.\n", repoName, format)
+        printMembers(repo.Group)
+    },
+}
+
+var repoGroupCmd = &cobra.Command{
+    Use:   "group",
+    Short: "Manage the members of a group repository",
+    Long: `Change the members of a group repository. Each command reads the group,
+changes its member list and writes it back; the order of the members is
+the order Nexus searches them in and is kept as is unless you change it.`,
+}
+
+var repoGroupAddMemberCmd = &cobra.Command{
+    Use:   "add-member <group> <member>...",
+    Short: "Add members to a group repository",
+    Long: `Add members to a group repository. They are appended unless a position
+is given with --position (1 is first), --before or --after.`,
+    Args: cobra.MinimumNArgs(2),
+    Run: func(cmd *cobra.Command, args []string) {
+        groupName, added := args[0], args[1:]
+
+        placement := 0
+        for _, set := range []bool{cmd.Flags().Changed("position"), groupBefore != "", groupAfter != ""} {
+            if set {
+                placement++
+            }
+        }
+        if placement > 1 {
+            fmt.Fprintln(os.Stderr, "Error: use only one of --position, --before and --after.")
+            os.Exit(ExitUsage)
+        }
+        if cmd.Flags().Changed("position") && groupPosition < 1 {
+            fmt.Fprintln(os.Stderr, "Error: --position must be 1 or more.")
+            os.Exit(ExitUsage)
+        }
+        added, err := uniqueMembers(added)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+
+        updateGroup(cmd.Context(), groupName, func(repo *client.Repository) error {
+            members := repo.Group.MemberNames
+            for _, m := range added {
+                if contains(members, m) {
+                    return usageError{fmt.Errorf("'%s' is already a member of '%s'", m, groupName)}
+                }
+            }
+            if err := checkMembers(cmd.Context(), repo.Format, groupName, added); err != nil {
+                return err
+            }
+
+            at, err := memberPosition(groupName, members, groupPosition, groupBefore, groupAfter)
+            if err != nil {
+                return err
+            }
+            updated := make([]string, 0, len(members)+len(added))
+            updated = append(updated, members[:at]...)
+            updated = append(updated, added...)
+            updated = append(updated, members[at:]...)
+            repo.Group.MemberNames = updated
+            return nil
+        })
+    },
+}
+
+var repoGroupRemoveMemberCmd = &cobra.Command{
+    Use:   "remove-member <group> <member>...",
+    Short: "Remove members from a group repository",
+    Args:  cobra.MinimumNArgs(2),
+    Run: func(cmd *cobra.Command, args []string) {
+        groupName, removed := args[0], args[1:]
+
+        updateGroup(cmd.Context(), groupName, func(repo *client.Repository) error {
+            for _, m := range removed {
+                if !contains(repo.Group.MemberNames, m) {
+                    return usageError{fmt.Errorf("'%s' is not a member of '%s'", m, groupName)}
+                }
+            }
+            updated := []string{}
+            for _, m := range repo.Group.MemberNames {
+                if !contains(removed, m) {
+                    updated = append(updated, m)
+                }
+            }
+            repo.Group.MemberNames = updated
+            if contains(removed, repo.Group.WritableMember) {
+                fmt.Fprintf(os.Stderr, "Note: '%s' was the writable member; the group no longer has one.\n", repo.Group.WritableMember)
+                repo.Group.WritableMember = ""
+            }
+            return nil
+        })
+    },
+}
+
+var repoGroupReorderCmd = &cobra.Command{
+    Use:   "reorder <group> <member>...",
+    Short: "Set the order of the members of a group repository",
+    Long: `Set the order in which Nexus searches the members of a group. List every
+current member exactly once, first searched first.`,
+    Example: `  nexuscli repo group reorder maven-public maven-releases maven-snapshots maven-central`,
+    Args: cobra.MinimumNArgs(2),
+    Run: func(cmd *cobra.Command, args []string) {
+        groupName := args[0]
+        order, err := uniqueMembers(args[1:])
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+
+        updateGroup(cmd.Context(), groupName, func(repo *client.Repository) error {
+            if err := checkOrder(groupName, repo.Group.MemberNames, order); err != nil {
+                return err
+            }
+            repo.Group.MemberNames = order
+            return nil
+        })
+    },
+}
+
+// memberPosition returns the index in members at which add-member inserts:
+// position (1 is first) if not 0, before or after the named member if
+// given, otherwise the end.
+func memberPosition(group string, members []string, position int, before, after string) (int, error) {
+    switch {
+    case position != 0:
+        if position < 1 || position > len(members)+1 {
+            return 0, usageError{fmt.Errorf("--position must be between 1 and %d", len(members)+1)}
+        }
+        return position - 1, nil
+    case before != "":
+        at := indexOf(members, before)
+        if at < 0 {
+            return 0, usageError{fmt.Errorf("'%s' is not a member of '%s'", before, group)}
+        }
+        return at, nil
+    case after != "":
+        at := indexOf(members, after)
+        if at < 0 {
+            return 0, usageError{fmt.Errorf("'%s' is not a member of '%s'", after, group)}
+        }
+        return at + 1, nil
+    }
+    return len(members), nil
+}
+
+// checkOrder verifies that order lists every member of the group and
+// nothing else.
+func checkOrder(group string, members, order []string) error {
+    var missing, unknown []string
+    for _, m := range members {
+        if !contains(order, m) {
+            missing = append(missing, m)
+        }
+    }
+    for _, m := range order {
+        if !contains(members, m) {
+            unknown = append(unknown, m)
+        }
+    }
+    if len(unknown) > 0 {
+        return usageError{fmt.Errorf("not members of '%s': %s (use add-member)", group, strings.Join(unknown, ", "))}
+    }
+    if len(missing) > 0 {
+        return usageError{fmt.Errorf("the new order leaves out %s; list every member", strings.Join(missing, ", "))}
+    }
+    return nil
+}
+
+// updateGroup reads a group repository, lets change edit its members and
+// writes it back. Nothing is written when change fails or leaves the
+// members as they were.
+func updateGroup(ctx context.Context, name string, change func(*client.Repository) error) {
+    repo, err := nexusClient.GetRepository(ctx, name)
+    if err != nil {
+        fail(err, "Error reading repository '%s'", name)
+    }
+    if repo.Type != "group" || repo.Group == nil {
+        fmt.Fprintf(os.Stderr, "Error: '%s' is a %s repository, not a group.\n", name, repo.Type)
+        os.Exit(ExitUsage)
+    }
+
+    before := strings.Join(repo.Group.MemberNames, ",")
+    if err := change(repo); err != nil {
+        fail(err, "Error updating group '%s'", name)
+    }
+    if strings.Join(repo.Group.MemberNames, ",") == before {
+        fmt.Printf("Group '%s' is unchanged.\n", name)
+        printMembers(repo.Group)
+        return
+    }
+
+    if err := nexusClient.UpdateRepository(ctx, *repo); err != nil {
+        fail(err, "Error updating group '%s'", name)
+    }
+    fmt.Printf("Group '%s' updated successfully.\n", name)
+    printMembers(repo.Group)
+}
+
+// checkMembers verifies that the members exist and share the group's
+// format, which Nexus would otherwise reject with a less helpful message.
+func checkMembers(ctx context.Context, format, group string, members []string) error {
+    if len(members) == 0 {
+        return nil
+    }
+    repos, err := nexusClient.ListRepositories(ctx, client.ListOptions{}).All()
+    if err != nil {
+        return err
+    }
+    formats := map[string]string{}
+    for _, r := range repos {
+        formats[r.Name] = r.Format
+    }
+    for _, m := range members {
+        f, ok := formats[m]
+        switch {
+        case m == group:
+            return usageError{fmt.Errorf("a group cannot be a member of itself")}
+        case !ok:
+            return notFoundError{fmt.Errorf("repository '%s' does not exist", m)}
+        case f != format:
+            return usageError{fmt.Errorf("'%s' is a %s repository, not %s", m, f, format)}
+        }
+    }
+    return nil
+}
+
+// uniqueMembers rejects empty and repeated member names.
+func uniqueMembers(members []string) ([]string, error) {
+    seen := map[string]bool{}
+    out := []string{}
+    for _, m := range members {
+        m = strings.TrimSpace(m)
+        if m == "" {
+            continue
+        }
+        if seen[m] {
+            return nil, fmt.Errorf("'%s' is listed more than once", m)
+        }
+        seen[m] = true
+        out = append(out, m)
+    }
+    return out, nil
+}
+
+func indexOf(list []string, s string) int {
+    for i, item := range list {
+        if item == s {
+            return i
+        }
+    }
+    return -1
+}
+
+func printMembers(g *client.Group) {
+    if len(g.MemberNames) == 0 {
+        fmt.Println("No members.")
+        return
+    }
+    fmt.Println("Members, in search order:")
+    for i, m := range g.MemberNames {
+        suffix := ""
+        if m == g.WritableMember {
+            suffix = " (writable)"
+        }
+        fmt.Printf("  %d. %s%s\n", i+1, m, suffix)
+    }
+}
+
+func init() {
+    repoCmd.AddCommand(repoCreateGroupCmd)
+    repoCmd.AddCommand(repoGroupCmd)
+    repoGroupCmd.AddCommand(repoGroupAddMemberCmd)
+    repoGroupCmd.AddCommand(repoGroupRemoveMemberCmd)
+    repoGroupCmd.AddCommand(repoGroupReorderCmd)
+
+    addStorageFlags(repoCreateGroupCmd)
+    f := repoCreateGroupCmd.Flags()
+    f.StringSliceVar(&groupMembers, "members", nil, "Member repositories, first searched first")
+    f.StringVar(&groupWritableMember, "writable-member", "", "Member that receives pushes to the group (docker and npm, Pro)")
+    addDockerFlags(repoCreateGroupCmd)
+
+    repoGroupAddMemberCmd.Flags().IntVar(&groupPosition, "position", 0, "Insert at this position, 1 being first")
+    repoGroupAddMemberCmd.Flags().StringVar(&groupBefore, "before", "", "Insert before this member")
+    repoGroupAddMemberCmd.Flags().StringVar(&groupAfter, "after", "", "Insert after this member")
+}
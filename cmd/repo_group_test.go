@@ -0,0 +1,115 @@
+package cmd
+
+import (
+    "context"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestMemberPosition(t *testing.T) {
+    members := []string{"releases", "snapshots", "central"}
+    tests := []struct {
+        name     string
+        position int
+        before   string
+        after    string
+        want     int
+        wantErr  string
+    }{
+        {name: "appended", want: 3},
+        {name: "first", position: 1, want: 0},
+        {name: "last", position: 4, want: 3},
+        {name: "past the end", position: 5, wantErr: "--position must be between 1 and 4"},
+        {name: "before", before: "snapshots", want: 1},
+        {name: "before the first", before: "releases", want: 0},
+        {name: "after", after: "snapshots", want: 2},
+        {name: "after the last", after: "central", want: 3},
+        {name: "before a non-member", before: "npmjs", wantErr: "'npmjs' is not a member of 'public'"},
+        {name: "after a non-member", after: "npmjs", wantErr: "'npmjs' is not a member of 'public'"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := memberPosition("public", members, tt.position, tt.before, tt.after)
+            if tt.wantErr != "" {
+                if err == nil || !strings.Contains(err.Error(), tt.wantErr) || exitCode(err) != ExitUsage {
+                    t.Errorf("got %d, %v, want a usage error about %q", got, err, tt.wantErr)
+                }
+                return
+            }
+            if err != nil || got != tt.want {
+                t.Errorf("got %d, %v, want %d", got, err, tt.want)
+            }
+        })
+    }
+}
+
+func TestCheckOrder(t *testing.T) {
+    members := []string{"releases", "snapshots", "central"}
+    tests := []struct {
+        name    string
+        order   []string
+        wantErr string
+    }{
+        {name: "same order", order: []string{"releases", "snapshots", "central"}},
+        {name: "new order", order: []string{"central", "releases", "snapshots"}},
+        {name: "member left out", order: []string{"central", "releases"}, wantErr: "leaves out snapshots"},
+        {name: "unknown member", order: []string{"central", "releases", "snapshots", "npmjs"}, wantErr: "not members of 'public': npmjs"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := checkOrder("public", members, tt.order)
+            if tt.wantErr == "" {
+                if err != nil {
+                    t.Errorf("checkOrder() = %v", err)
+                }
+                return
+            }
+            if err == nil || !strings.Contains(err.Error(), tt.wantErr) || exitCode(err) != ExitUsage {
+                t.Errorf("checkOrder() = %v, want a usage error about %q", err, tt.wantErr)
+            }
+        })
+    }
+}
+
+func TestUniqueMembers(t *testing.T) {
+    tests := []struct {
+        in      []string
+        want    []string
+        wantErr bool
+    }{
+        {in: []string{"a", " b ", "", "c"}, want: []string{"a", "b", "c"}},
+        {in: []string{}, want: []string{}},
+        {in: []string{"a", "b", "a "}, wantErr: true},
+    }
+    for _, tt := range tests {
+        got, err := uniqueMembers(tt.in)
+        if (err != nil) != tt.wantErr || !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("uniqueMembers(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+        }
+    }
+}
+
+func TestCheckMembers(t *testing.T) {
+    useTestServer(t, serveRepositories)
+
+    tests := []struct {
+        name     string
+        members  []string
+        wantCode int
+    }{
+        {name: "no members", members: nil},
+        {name: "members of the format", members: []string{"maven-releases", "maven-central"}},
+        {name: "missing member", members: []string{"maven-releases", "nope"}, wantCode: ExitNotFound},
+        {name: "member of another format", members: []string{"npmjs"}, wantCode: ExitUsage},
+        {name: "the group itself", members: []string{"maven-public"}, wantCode: ExitUsage},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := checkMembers(context.Background(), "maven2", "maven-public", tt.members)
+            if got := exitCode(err); got != tt.wantCode {
+                t.Errorf("got %v (exit code %d), want exit code %d", err, got, tt.wantCode)
+            }
+        })
+    }
+}
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "regexp"
+    "sort"
+    "strings"
+    "sync"
+    "nexuscli/internal/client"
+    "nexuscli/internal/output"
+    "github.com/spf13/cobra"
+)
+
+var (
+    listOnline  bool
+    listOffline bool
+    listName    string
+    listSortBy  string
+)
+
+// repoColumns are the columns of repo list. -o wide adds repoWideColumns,
+// which come from the detail endpoint of every repository.
+var (
+    repoColumns     = []string{"NAME", "FORMAT", "TYPE", "URL", "ONLINE"}
+    repoWideColumns = []string{"BLOB STORE", "WRITE POLICY", "CLEANUP", "REMOTE URL", "MEMBERS"}
+)
+
+// detailWorkers is the number of repository details fetched at once.
+const detailWorkers = 8
+
+var repoListCmd = &cobra.Command{
+    Use:   "list",
+    Short: "List all Nexus repositories",
+    Long: `List repositories, optionally filtered by format, type, online state and
+name. --name takes a glob such as "npm-*", or a regular expression between
+slashes such as "/^(npm|yarn)-/".
+
+-o wide adds the blob store, write policy, cleanup policies, the remote of
+proxies and the number of members of groups. --sort-by takes any column,
+e.g. --sort-by type or --sort-by "blob store" with -o wide.`,
+    Example: `  nexuscli repo list --format npm --type proxy
+  nexuscli repo list --offline
+  nexuscli repo list --name 'maven-*' -o wide --sort-by "blob store"`,
+    Args: cobra.NoArgs,
+    Run: func(cmd *cobra.Command, args []string) {
+        ctx := cmd.Context()
+        wide := strings.ToLower(outputFormat) == "wide"
+        headers := repoColumns
+        if wide {
+            headers = append(append([]string{}, repoColumns...), repoWideColumns...)
+        }
+
+        matchName, err := nameMatcher("--name", listName)
+        if err == nil && listOnline && listOffline {
+            err = fmt.Errorf("use only one of --online and --offline")
+        }
+        if err == nil && selectType != "" {
+            err = checkEnum("--type", strings.ToLower(selectType), "hosted", "proxy", "group")
+        }
+        sortColumn := ""
+        if err == nil && listSortBy != "" {
+            sortColumn, err = columnName(listSortBy, headers)
+        }
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+
+        // --limit applies to what is left after filtering
+        all, err := nexusClient.ListRepositories(ctx, client.ListOptions{PageSize: listPageSize}).All()
+        if err != nil {
+            fail(err, "Error listing repositories")
+        }
+        format := normalizeFormat(selectFormat)
+        repos := []client.Repository{}
+        for _, r := range all {
+            if (format != "" && r.Format != format) || (selectType != "" && r.Type != strings.ToLower(selectType)) || !matchName(r.Name) {
+                continue
+            }
+            repos = append(repos, r)
+        }
+
+        // older versions of Nexus leave the online state out of the list
+        needDetails := []client.Repository{}
+        for _, r := range repos {
+            if wide || ((listOnline || listOffline) && r.Online == nil) {
+                needDetails = append(needDetails, r)
+            }
+        }
+        details, err := repositoryDetails(ctx, needDetails)
+        if err != nil {
+            fail(err, "Error listing repositories")
+        }
+        if listOnline || listOffline {
+            kept := []client.Repository{}
+            for _, r := range repos {
+                online := r.Online
+                if d := details[r.Name]; online == nil && d != nil {
+                    online = d.Online
+                }
+                if online != nil && *online == listOnline {
+                    kept = append(kept, r)
+                }
+            }
+            repos = kept
+        }
+
+        if len(repos) == 0 {
+            fmt.Println("No repositories found.")
+            return
+        }
+
+        items := []map[string]interface{}{}
+        for _, repo := range repos {
+            item := map[string]interface{}{
+                "NAME":   repo.Name,
+                "FORMAT": repo.Format,
+                "TYPE":   repo.Type,
+                "URL":    repo.URL,
+                "ONLINE": optBool(repo.Online),
+            }
+            if d := details[repo.Name]; d != nil {
+                if repo.Online == nil {
+                    item["ONLINE"] = optBool(d.Online)
+                }
+                if wide {
+                    addWideColumns(item, d)
+                }
+            }
+            items = append(items, item)
+        }
+        if sortColumn != "" {
+            sort.SliceStable(items, func(i, j int) bool {
+                return lessCell(items[i][sortColumn], items[j][sortColumn])
+            })
+        }
+        if listLimit > 0 && len(items) > listLimit {
+            items = items[:listLimit]
+        }
+
+        output.Render(items, outputFormat, headers, func(r map[string]interface{}) {
+            fmt.Printf("\033[32m%s\033[0m\t%s\t%s\t%s\t%v\n",
+                r["NAME"], r["FORMAT"], r["TYPE"], r["URL"], r["ONLINE"])
+        })
+    },
+}
+
+func addWideColumns(item map[string]interface{}, repo *client.Repository) {
+    if repo.Storage != nil {
+        item["BLOB STORE"] = repo.Storage.BlobStoreName
+        item["WRITE POLICY"] = repo.Storage.WritePolicy
+    }
+    if repo.Cleanup != nil {
+        item["CLEANUP"] = strings.Join(repo.Cleanup.PolicyNames, ",")
+    }
+    if repo.Proxy != nil {
+        item["REMOTE URL"] = repo.Proxy.RemoteURL
+    }
+    if repo.Group != nil {
+        item["MEMBERS"] = len(repo.Group.MemberNames)
+    }
+}
+
+// repositoryDetails fetches the full configuration of repos, detailWorkers
+// at a time. Repositories whose details cannot be read are reported and
+// left out; only cancellation is an error.
+func repositoryDetails(ctx context.Context, repos []client.Repository) (map[string]*client.Repository, error) {
+    details := make(map[string]*client.Repository, len(repos))
+    var mu sync.Mutex
+    var wg sync.WaitGroup
+    names := make(chan string)
+    for i := 0; i < detailWorkers; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for name := range names {
+                repo, err := nexusClient.GetRepository(ctx, name)
+                mu.Lock()
+                switch {
+                case err == nil:
+                    details[name] = repo
+                case ctx.Err() == nil && !errors.Is(err, client.ErrDryRun):
+                    fmt.Fprintf(os.Stderr, "Warning: could not read the settings of '%s': %v\n", name, err)
+                }
+                mu.Unlock()
+            }
+        }()
+    }
+
+feed:
+    for _, r := range repos {
+        select {
+        case names <- r.Name:
+        case <-ctx.Done():
+            break feed
+        }
+    }
+    close(names)
+    wg.Wait()
+    return details, ctx.Err()
+}
+
+// nameMatcher compiles the pattern of a filter flag: a glob, where * also
+// matches "/", or a regular expression between slashes.
+func nameMatcher(flag, pattern string) (func(string) bool, error) {
+    if pattern == "" {
+        return func(string) bool { return true }, nil
+    }
+    expr := ""
+    if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+        expr = pattern[1 : len(pattern)-1]
+    } else {
+        var err error
+        if expr, err = globExpr(pattern); err != nil {
+            return nil, fmt.Errorf("%s: %v", flag, err)
+        }
+    }
+    re, err := regexp.Compile(expr)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %v", flag, err)
+    }
+    return re.MatchString, nil
+}
+
+// globExpr translates a glob with *, ?, [classes] and \ escapes into an
+// anchored regular expression.
+func globExpr(glob string) (string, error) {
+    var b strings.Builder
+    b.WriteString("^")
+    for i := 0; i < len(glob); i++ {
+        switch glob[i] {
+        case '*':
+            b.WriteString(".*")
+        case '?':
+            b.WriteString(".")
+        case '[':
+            end := strings.IndexByte(glob[i+1:], ']')
+            if end < 0 {
+                return "", fmt.Errorf("invalid glob %q: unclosed [", glob)
+            }
+            class := glob[i+1 : i+1+end]
+            if strings.HasPrefix(class, "!") {
+                class = "^" + class[1:]
+            }
+            b.WriteString("[" + class + "]")
+            i += end + 1
+        case '\\':
+            if i+1 < len(glob) {
+                i++
+            }
+            b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+        default:
+            b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+        }
+    }
+    b.WriteString("$")
+    return b.String(), nil
+}
+
+// columnName finds the column --sort-by names, ignoring case, spaces,
+// dashes and underscores.
+func columnName(name string, columns []string) (string, error) {
+    key := func(s string) string {
+        return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToUpper(s))
+    }
+    for _, c := range columns {
+        if key(c) == key(name) {
+            return c, nil
+        }
+    }
+    for _, c := range repoWideColumns {
+        if key(c) == key(name) {
+            return "", fmt.Errorf("--sort-by %s needs -o wide", strings.ToLower(c))
+        }
+    }
+    return "", fmt.Errorf("--sort-by must be one of %s", strings.ToLower(strings.Join(columns, ", ")))
+}
+
+// lessCell orders table cells: numbers and booleans by value, the rest as
+// case-insensitive text, empty cells first.
+func lessCell(a, b interface{}) bool {
+    switch x := a.(type) {
+    case int:
+        if y, ok := b.(int); ok {
+            return x < y
+        }
+    case bool:
+        if y, ok := b.(bool); ok {
+            return !x && y
+        }
+    }
+    text := func(v interface{}) string {
+        if v == nil {
+            return ""
+        }
+        return strings.ToLower(fmt.Sprint(v))
+    }
+    return text(a) < text(b)
+}
+
+func init() {
+    repoCmd.AddCommand(repoListCmd)
+
+    addListFlags(repoListCmd)
+    f := repoListCmd.Flags()
+    f.StringVar(&selectFormat, "format", "", "Only list repositories of this format")
+    f.StringVar(&selectType, "type", "", "Only list repositories of this type: hosted, proxy or group")
+    f.BoolVar(&listOnline, "online", false, "Only list online repositories")
+    f.BoolVar(&listOffline, "offline", false, "Only list offline repositories")
+    f.StringVar(&listName, "name", "", "Only list repositories whose name matches this glob or /regular expression/")
+    f.StringVar(&listSortBy, "sort-by", "", "Sort by this column")
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+    "sort"
+    "strings"
+    "testing"
+)
+
+func TestGlobExpr(t *testing.T) {
+    tests := []struct {
+        glob    string
+        want    string
+        wantErr bool
+    }{
+        {glob: "maven-*", want: `^maven-.*$`},
+        {glob: "npm?", want: `^npm.$`},
+        {glob: "v1.2", want: `^v1\.2$`},
+        {glob: "[abc]-x", want: `^[abc]-x$`},
+        {glob: "[!abc]", want: `^[^abc]$`},
+        {glob: `a\*b`, want: `^a\*b$`},
+        {glob: `trailing\`, want: `^trailing\\$`},
+        {glob: "[abc", wantErr: true},
+    }
+    for _, tt := range tests {
+        got, err := globExpr(tt.glob)
+        if (err != nil) != tt.wantErr || got != tt.want {
+            t.Errorf("globExpr(%q) = %q, %v, want %q (error %v)", tt.glob, got, err, tt.want, tt.wantErr)
+        }
+    }
+}
+
+func TestNameMatcher(t *testing.T) {
+    tests := []struct {
+        pattern string
+        matches []string
+        misses  []string
+        wantErr bool
+    }{
+        {pattern: "", matches: []string{"", "anything"}},
+        {pattern: "maven-*", matches: []string{"maven-", "maven-central"}, misses: []string{"npm-maven-x", "Maven-central"}},
+        {pattern: "team/*", matches: []string{"team/app", "team/a/b"}, misses: []string{"other/team/app"}},
+        {pattern: "1.2.?", matches: []string{"1.2.3"}, misses: []string{"1.2.10", "1x2.3"}},
+        {pattern: "/^@acme\\//", matches: []string{"@acme/ui"}, misses: []string{"@other/ui"}},
+        {pattern: "/snap/", matches: []string{"maven-snapshots"}, misses: []string{"maven-releases"}},
+        {pattern: "/", matches: []string{"/"}, misses: []string{"a"}},
+        {pattern: "/(/", wantErr: true},
+        {pattern: "[x", wantErr: true},
+    }
+    for _, tt := range tests {
+        match, err := nameMatcher("--name", tt.pattern)
+        if tt.wantErr {
+            if err == nil || !strings.HasPrefix(err.Error(), "--name: ") {
+                t.Errorf("nameMatcher(%q) = %v, want an error naming the flag", tt.pattern, err)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("nameMatcher(%q): %v", tt.pattern, err)
+            continue
+        }
+        for _, s := range tt.matches {
+            if !match(s) {
+                t.Errorf("%q does not match %q", tt.pattern, s)
+            }
+        }
+        for _, s := range tt.misses {
+            if match(s) {
+                t.Errorf("%q matches %q", tt.pattern, s)
+            }
+        }
+    }
+}
+
+func TestColumnName(t *testing.T) {
+    wide := append(append([]string{}, repoColumns...), repoWideColumns...)
+    tests := []struct {
+        name    string
+        columns []string
+        want    string
+        wantErr string
+    }{
+        {name: "name", columns: repoColumns, want: "NAME"},
+        {name: "Online", columns: repoColumns, want: "ONLINE"},
+        {name: "blob store", columns: wide, want: "BLOB STORE"},
+        {name: "write-policy", columns: wide, want: "WRITE POLICY"},
+        {name: "remote_url", columns: wide, want: "REMOTE URL"},
+        {name: "members", columns: repoColumns, wantErr: "needs -o wide"},
+        {name: "size", columns: repoColumns, wantErr: "must be one of name, format, type, url, online"},
+    }
+    for _, tt := range tests {
+        got, err := columnName(tt.name, tt.columns)
+        if tt.wantErr != "" {
+            if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                t.Errorf("columnName(%q) = %q, %v, want error %q", tt.name, got, err, tt.wantErr)
+            }
+            continue
+        }
+        if err != nil || got != tt.want {
+            t.Errorf("columnName(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+        }
+    }
+}
+
+func TestLessCell(t *testing.T) {
+    tests := []struct {
+        cells []interface{}
+        want  []interface{}
+    }{
+        {[]interface{}{10, 2, 33}, []interface{}{2, 10, 33}},
+        {[]interface{}{true, false}, []interface{}{false, true}},
+        {[]interface{}{"npm", "Maven", "docker"}, []interface{}{"docker", "Maven", "npm"}},
+        {[]interface{}{"b", nil, "", "a"}, []interface{}{nil, "", "a", "b"}},
+    }
+    for _, tt := range tests {
+        got := append([]interface{}{}, tt.cells...)
+        sort.SliceStable(got, func(i, j int) bool { return lessCell(got[i], got[j]) })
+        for i := range got {
+            if got[i] != tt.want[i] {
+                t.Errorf("sorted %v to %v, want %v", tt.cells, got, tt.want)
+                break
+            }
+        }
+    }
+}
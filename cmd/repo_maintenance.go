@@ -0,0 +1,214 @@
+package cmd
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+    "nexuscli/internal/client"
+    "github.com/spf13/cobra"
+)
+
+// Selector of the repository maintenance commands and of repo list.
+var (
+    selectFormat string
+    selectType   string
+)
+
+const selectorHelp = `
+Name the repositories, or select them with --format and --type instead:
+each repository is handled in turn and a failure does not stop the others.`
+
+var repoOfflineCmd = &cobra.Command{
+    Use:   "offline [repo_name...]",
+    Short: "Take repositories offline",
+    Long: `Take repositories offline. Nexus rejects requests to an offline repository
+and, for a member of a group, skips it when resolving the group.` + selectorHelp,
+    Example: `  nexuscli repo offline maven-central npmjs
+  nexuscli repo offline --format docker --type proxy`,
+    Run: func(cmd *cobra.Command, args []string) {
+        eachRepository(cmd, args, nil, setOnline(false))
+    },
+}
+
+var repoOnlineCmd = &cobra.Command{
+    Use:   "online [repo_name...]",
+    Short: "Bring repositories back online",
+    Long:  `Bring offline repositories back online.` + selectorHelp,
+    Example: `  nexuscli repo online maven-central npmjs
+  nexuscli repo online --format docker`,
+    Run: func(cmd *cobra.Command, args []string) {
+        eachRepository(cmd, args, nil, setOnline(true))
+    },
+}
+
+var repoRebuildIndexCmd = &cobra.Command{
+    Use:   "rebuild-index [repo_name...]",
+    Short: "Rebuild the search index of repositories",
+    Long: `Rebuild the search index of repositories, e.g. when search or browse
+misses components that are in the blob store. Nexus rebuilds the index in
+the background.` + selectorHelp,
+    Example: `  nexuscli repo rebuild-index maven-releases
+  nexuscli repo rebuild-index --format maven --type hosted`,
+    Run: func(cmd *cobra.Command, args []string) {
+        eachRepository(cmd, args, nil, func(ctx context.Context, repo client.Repository) (string, error) {
+            if err := nexusClient.RebuildIndex(ctx, repo.Name); err != nil {
+                return "", err
+            }
+            return fmt.Sprintf("Rebuilding the index of '%s'.", repo.Name), nil
+        })
+    },
+}
+
+var repoInvalidateCacheCmd = &cobra.Command{
+    Use:   "invalidate-cache [repo_name...]",
+    Short: "Invalidate the cache of proxy and group repositories",
+    Long: `Invalidate the cache of proxy and group repositories, so content and
+metadata are fetched from the remote again on the next request. Use it
+after an upstream incident served broken or stale content.` + selectorHelp + `
+Hosted repositories have no cache and are left out of a selection.`,
+    Example: `  nexuscli repo invalidate-cache npmjs
+  nexuscli repo invalidate-cache --format npm --type proxy`,
+    Run: func(cmd *cobra.Command, args []string) {
+        eachRepository(cmd, args, []string{"proxy", "group"}, func(ctx context.Context, repo client.Repository) (string, error) {
+            if err := nexusClient.InvalidateCache(ctx, repo.Name); err != nil {
+                return "", err
+            }
+            return fmt.Sprintf("Cache of '%s' invalidated.", repo.Name), nil
+        })
+    },
+}
+
+// setOnline reads a repository and writes it back with its online flag
+// set, unless it already has that state.
+func setOnline(online bool) func(context.Context, client.Repository) (string, error) {
+    state := map[bool]string{true: "online", false: "offline"}[online]
+    return func(ctx context.Context, repo client.Repository) (string, error) {
+        full, err := nexusClient.GetRepository(ctx, repo.Name)
+        if err != nil {
+            return "", err
+        }
+        if full.Online != nil && *full.Online == online {
+            return fmt.Sprintf("Repository '%s' is already %s.", repo.Name, state), nil
+        }
+        before, err := cloneRepository(full)
+        if err != nil {
+            return "", err
+        }
+        full.Online = &online
+        keepStoredPassword(before, full)
+        if err := nexusClient.UpdateRepository(ctx, *full); err != nil {
+            return "", err
+        }
+        return fmt.Sprintf("Repository '%s' is now %s.", repo.Name, state), nil
+    }
+}
+
+// eachRepository runs action on the repositories named in args or picked
+// by --format and --type, one after the other, and exits with the code of
+// the first failure. types limits the repository types action applies to;
+// nil means all.
+func eachRepository(cmd *cobra.Command, args []string, types []string, action func(context.Context, client.Repository) (string, error)) {
+    ctx := cmd.Context()
+    repos, err := selectRepositories(ctx, args, types)
+    if err != nil {
+        fail(err, "Error selecting repositories")
+    }
+
+    var firstErr error
+    failed := 0
+    for i, repo := range repos {
+        if ctx.Err() != nil {
+            fail(ctx.Err(), "Stopped with %d of %d repositories left", len(repos)-i, len(repos))
+        }
+        msg, err := action(ctx, repo)
+        if errors.Is(err, client.ErrDryRun) {
+            continue
+        }
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %s: %v\n", repo.Name, err)
+            failed++
+            if firstErr == nil {
+                firstErr = err
+            }
+            continue
+        }
+        fmt.Println(msg)
+    }
+
+    if failed > 0 {
+        if len(repos) > 1 {
+            fmt.Fprintf(os.Stderr, "%d of %d repositories failed.\n", failed, len(repos))
+        }
+        os.Exit(exitCode(firstErr))
+    }
+}
+
+// selectRepositories resolves the repositories named in names, or those
+// matching --format and --type when no names are given.
+func selectRepositories(ctx context.Context, names []string, types []string) ([]client.Repository, error) {
+    format := normalizeFormat(selectFormat)
+    repoType := strings.ToLower(selectType)
+    switch {
+    case len(names) > 0 && (format != "" || repoType != ""):
+        return nil, usageError{fmt.Errorf("give repository names or --format/--type, not both")}
+    case len(names) == 0 && format == "" && repoType == "":
+        return nil, usageError{fmt.Errorf("name a repository, or select them with --format and --type")}
+    }
+    if repoType != "" {
+        if err := checkEnum("--type", repoType, "hosted", "proxy", "group"); err != nil {
+            return nil, usageError{err}
+        }
+        if types != nil && !contains(types, repoType) {
+            return nil, usageError{fmt.Errorf("this command only applies to %s repositories", strings.Join(types, " and "))}
+        }
+    }
+
+    all, err := nexusClient.ListRepositories(ctx, client.ListOptions{}).All()
+    if err != nil {
+        return nil, err
+    }
+
+    if len(names) > 0 {
+        byName := map[string]client.Repository{}
+        for _, r := range all {
+            byName[r.Name] = r
+        }
+        selected := []client.Repository{}
+        for _, name := range names {
+            r, ok := byName[name]
+            if !ok {
+                return nil, notFoundError{fmt.Errorf("repository '%s' does not exist", name)}
+            }
+            if types != nil && !contains(types, r.Type) {
+                return nil, usageError{fmt.Errorf("'%s' is a %s repository; this command only applies to %s repositories", name, r.Type, strings.Join(types, " and "))}
+            }
+            selected = append(selected, r)
+        }
+        return selected, nil
+    }
+
+    selected := []client.Repository{}
+    for _, r := range all {
+        if (format != "" && r.Format != format) || (repoType != "" && r.Type != repoType) {
+            continue
+        }
+        if types != nil && !contains(types, r.Type) {
+            continue
+        }
+        selected = append(selected, r)
+    }
+    if len(selected) == 0 {
+        fmt.Fprintln(os.Stderr, "No repositories match the selection.")
+    }
+    return selected, nil
+}
+
+func init() {
+    for _, c := range []*cobra.Command{repoOfflineCmd, repoOnlineCmd, repoRebuildIndexCmd, repoInvalidateCacheCmd} {
+        repoCmd.AddCommand(c)
+        c.Flags().StringVar(&selectFormat, "format", "", "Select the repositories of this format")
+        c.Flags().StringVar(&selectType, "type", "", "Select the repositories of this type: hosted, proxy or group")
+    }
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "testing"
+    "nexuscli/internal/client"
+)
+
+const testRepositories = `[
+    {"name": "maven-releases", "format": "maven2", "type": "hosted"},
+    {"name": "maven-central", "format": "maven2", "type": "proxy"},
+    {"name": "maven-public", "format": "maven2", "type": "group"},
+    {"name": "npmjs", "format": "npm", "type": "proxy"}
+]`
+
+// useTestServer points nexusClient at an httptest server running handler
+// for the rest of the test.
+func useTestServer(t *testing.T, handler http.HandlerFunc) {
+    t.Helper()
+    srv := httptest.NewServer(handler)
+    t.Cleanup(srv.Close)
+    c, err := client.New(srv.URL, client.WithRetryPolicy(client.RetryPolicy{}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    old := nexusClient
+    nexusClient = c
+    t.Cleanup(func() { nexusClient = old })
+}
+
+// serveRepositories answers the repository list with testRepositories.
+func serveRepositories(w http.ResponseWriter, r *http.Request) {
+    if r.URL.Path != "/service/rest/v1/repositories" {
+        http.NotFound(w, r)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.Write([]byte(testRepositories))
+}
+
+func TestSelectRepositories(t *testing.T) {
+    useTestServer(t, serveRepositories)
+
+    tests := []struct {
+        name     string
+        names    []string
+        format   string
+        repoType string
+        types    []string
+        want     []string
+        wantCode int
+    }{
+        {name: "by name", names: []string{"npmjs", "maven-releases"}, want: []string{"npmjs", "maven-releases"}},
+        {name: "by format", format: "maven", want: []string{"maven-releases", "maven-central", "maven-public"}},
+        {name: "by format and type", format: "maven2", repoType: "Proxy", want: []string{"maven-central"}},
+        {name: "selection outside the types", format: "maven2", types: []string{"proxy", "group"}, want: []string{"maven-central", "maven-public"}},
+        {name: "nothing matches", format: "docker", want: []string{}},
+        {name: "missing repository", names: []string{"npmjs", "nope"}, wantCode: ExitNotFound},
+        {name: "named repository of another type", names: []string{"maven-releases"}, types: []string{"proxy", "group"}, wantCode: ExitUsage},
+        {name: "type outside the types", repoType: "hosted", types: []string{"proxy", "group"}, wantCode: ExitUsage},
+        {name: "unknown type", repoType: "virtual", wantCode: ExitUsage},
+        {name: "names and selector", names: []string{"npmjs"}, format: "npm", wantCode: ExitUsage},
+        {name: "no selection", wantCode: ExitUsage},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            selectFormat, selectType = tt.format, tt.repoType
+            defer func() { selectFormat, selectType = "", "" }()
+
+            repos, err := selectRepositories(context.Background(), tt.names, tt.types)
+            if tt.wantCode != ExitOK {
+                if got := exitCode(err); got != tt.wantCode {
+                    t.Fatalf("got %v (exit code %d), want exit code %d", err, got, tt.wantCode)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatal(err)
+            }
+            got := []string{}
+            for _, r := range repos {
+                got = append(got, r.Name)
+            }
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("selected %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestSetOnline(t *testing.T) {
+    tests := []struct {
+        name       string
+        online     bool
+        stored     string
+        wantPut    string
+        wantOutput string
+    }{
+        {
+            name:       "takes offline",
+            online:     false,
+            stored:     `{"name": "npmjs", "format": "npm", "type": "proxy", "online": true, "custom": 1}`,
+            wantPut:    `{"custom":1,"name":"npmjs","online":false}`,
+            wantOutput: "Repository 'npmjs' is now offline.",
+        },
+        {
+            name:       "already online",
+            online:     true,
+            stored:     `{"name": "npmjs", "format": "npm", "type": "proxy", "online": true}`,
+            wantOutput: "Repository 'npmjs' is already online.",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var put string
+            useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+                switch {
+                case r.Method == "GET":
+                    w.Write([]byte(tt.stored))
+                case r.Method == "PUT" && r.URL.Path == "/service/rest/v1/repositories/npm/proxy/npmjs":
+                    body, _ := io.ReadAll(r.Body)
+                    put = string(body)
+                    w.WriteHeader(http.StatusNoContent)
+                default:
+                    http.NotFound(w, r)
+                }
+            })
+
+            msg, err := setOnline(tt.online)(context.Background(), client.Repository{Name: "npmjs"})
+            if err != nil {
+                t.Fatal(err)
+            }
+            if msg != tt.wantOutput {
+                t.Errorf("got %q, want %q", msg, tt.wantOutput)
+            }
+            if tt.wantPut == "" {
+                if put != "" {
+                    t.Errorf("sent %s, want no update", put)
+                }
+                return
+            }
+            var got, want interface{}
+            json.Unmarshal([]byte(put), &got)
+            json.Unmarshal([]byte(tt.wantPut), &want)
+            if !reflect.DeepEqual(got, want) {
+                t.Errorf("sent %s, want %s", put, tt.wantPut)
+            }
+        })
+    }
+}
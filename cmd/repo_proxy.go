@@ -0,0 +1,493 @@
+package cmd
+
+import (
+    "fmt"
+    "io"
+    "net/url"
+    "os"
+    "strings"
+    "nexuscli/internal/client"
+    "github.com/spf13/cobra"
+    "github.com/spf13/pflag"
+)
+
+// Settings shared by the repository create commands.
+var (
+    repoBlobStore       string
+    repoStrictContent   bool
+    repoOnline          bool
+    repoCleanupPolicies []string
+    repoRoutingRule     string
+)
+
+// Settings of proxy repositories.
+var (
+    proxyRemoteURL         string
+    proxyContentMaxAge     int
+    proxyMetadataMaxAge    int
+    proxyNegativeCache     bool
+    proxyNegativeCacheTTL  int
+    proxyRemoteUsername    string
+    proxyRemotePassword    string
+    proxyPasswordStdin     bool
+    proxyAuthType          string
+    proxyNTLMHost          string
+    proxyNTLMDomain        string
+    proxyAutoBlock         bool
+    proxyBlocked           bool
+    proxyHTTPTimeout       int
+    proxyHTTPRetries       int
+    proxyUserAgentSuffix   string
+    proxyEnableCookies     bool
+    proxyCircularRedirects bool
+    proxyUseTrustStore     bool
+)
+
+// Format specific settings.
+var (
+    dockerV1Enabled          bool
+    dockerForceBasicAuth     bool
+    dockerHTTPPort           int
+    dockerHTTPSPort          int
+    dockerSubdomain          string
+    dockerIndexType          string
+    dockerIndexURL           string
+    dockerCacheForeignLayers bool
+    dockerForeignLayerURLs   []string
+
+    mavenVersionPolicy      string
+    mavenLayoutPolicy       string
+    mavenContentDisposition string
+
+    repoRemoveNonCataloged bool
+    repoRemoveQuarantined  bool
+
+    nugetVersion          string
+    nugetQueryCacheMaxAge int
+
+    aptDistribution string
+    aptFlat         bool
+)
+
+// proxyFormats are the formats Nexus can proxy.
+var proxyFormats = []string{
+    "apt", "cargo", "cocoapods", "conan", "conda", "docker", "go", "helm",
+    "maven2", "npm", "nuget", "p2", "pypi", "r", "raw", "rubygems", "yum",
+}
+
+// formatFlags lists the flags that only apply to some formats.
+var formatFlags = map[string][]string{
+    "docker-v1-enabled":           {"docker"},
+    "docker-force-basic-auth":     {"docker"},
+    "docker-http-port":            {"docker"},
+    "docker-https-port":           {"docker"},
+    "docker-subdomain":            {"docker"},
+    "docker-index-type":           {"docker"},
+    "docker-index-url":            {"docker"},
+    "docker-cache-foreign-layers": {"docker"},
+    "docker-foreign-layer-url":    {"docker"},
+    "maven-version-policy":        {"maven2"},
+    "maven-layout-policy":         {"maven2"},
+    "maven-content-disposition":   {"maven2"},
+    "remove-non-cataloged":        {"npm"},
+    "remove-quarantined":          {"npm", "pypi"},
+    "nuget-version":               {"nuget"},
+    "nuget-query-cache-max-age":   {"nuget"},
+    "apt-distribution":            {"apt"},
+    "apt-flat":                    {"apt"},
+    "apt-keypair-file":            {"apt"},
+    "apt-passphrase":              {"apt"},
+    "apt-passphrase-stdin":        {"apt"},
+    "yum-repodata-depth":          {"yum"},
+    "yum-deploy-policy":           {"yum"},
+}
+
+var repoCreateProxyCmd = &cobra.Command{
+    Use:   "create-proxy <format> <repo_name>",
+    Short: "Create a proxy repository",
+    Long: `Create a repository that proxies and caches a remote one, such as Maven
+Central, the npm registry, PyPI or Docker Hub.
+
+Formats: ` + strings.Join(proxyFormats, ", ") + ` ("maven" is accepted for maven2).
+Flags starting with a format name, and --remove-*, only apply to that format.
+Max ages and time to live are in minutes; -1 caches forever.`,
+    Example: `  nexuscli repo create-proxy maven maven-central --remote-url https://repo1.maven.org/maven2/
+  nexuscli repo create-proxy npm npmjs --remote-url https://registry.npmjs.org
+  nexuscli repo create-proxy pypi pypi-proxy --remote-url https://pypi.org
+  nexuscli repo create-proxy docker docker-hub --remote-url https://registry-1.docker.io --docker-http-port 8082`,
+    Args: cobra.ExactArgs(2),
+    Run: func(cmd *cobra.Command, args []string) {
+        format := normalizeFormat(args[0])
+        repoName := args[1]
+
+        if err := readStdinSecrets(cmd); err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+        repo, err := buildProxyRepository(cmd, format, repoName)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+
+        if err := nexusClient.CreateProxyRepository(cmd.Context(), *repo); err != nil {
+            fail(err, "Error creating proxy repository '%s'", repoName)
+        }
+        fmt.Printf("Proxy repository '%s' of format '%s' for %s created successfully.\n", repoName, format, proxyRemoteURL)
+    },
+}
+
+// normalizeFormat accepts the names formats go by in the API paths.
+func normalizeFormat(format string) string {
+    format = strings.ToLower(format)
+    if format == "maven" {
+        return "maven2"
+    }
+    return format
+}
+
+func buildProxyRepository(cmd *cobra.Command, format, name string) (*client.Repository, error) {
+    if !contains(proxyFormats, format) {
+        return nil, fmt.Errorf("unsupported proxy format '%s' (use %s)", format, strings.Join(proxyFormats, ", "))
+    }
+    if err := checkFormatFlags(cmd, format); err != nil {
+        return nil, err
+    }
+    if proxyRemoteURL == "" {
+        return nil, fmt.Errorf("--remote-url is required")
+    }
+    if err := checkRemoteURL("--remote-url", proxyRemoteURL); err != nil {
+        return nil, err
+    }
+
+    repo := &client.Repository{
+        Name:   name,
+        Format: format,
+        Online: &repoOnline,
+        Storage: &client.Storage{
+            BlobStoreName:               repoBlobStore,
+            StrictContentTypeValidation: repoStrictContent,
+        },
+        Proxy: &client.ProxyConfig{
+            RemoteURL:      proxyRemoteURL,
+            ContentMaxAge:  proxyContentMaxAge,
+            MetadataMaxAge: proxyMetadataMaxAge,
+        },
+        NegativeCache: &client.NegativeCache{
+            Enabled:    proxyNegativeCache,
+            TimeToLive: proxyNegativeCacheTTL,
+        },
+        HTTPClient: &client.HTTPClient{
+            Blocked:   proxyBlocked,
+            AutoBlock: proxyAutoBlock,
+            Connection: &client.HTTPConnection{
+                UserAgentSuffix:         proxyUserAgentSuffix,
+                EnableCircularRedirects: proxyCircularRedirects,
+                EnableCookies:           proxyEnableCookies,
+                UseTrustStore:           proxyUseTrustStore,
+            },
+        },
+        RoutingRuleName: repoRoutingRule,
+    }
+    if len(repoCleanupPolicies) > 0 {
+        repo.Cleanup = &client.Cleanup{PolicyNames: repoCleanupPolicies}
+    }
+    if cmd.Flags().Changed("http-timeout") {
+        repo.HTTPClient.Connection.Timeout = &proxyHTTPTimeout
+    }
+    if cmd.Flags().Changed("http-retries") {
+        repo.HTTPClient.Connection.Retries = &proxyHTTPRetries
+    }
+
+    auth, err := remoteAuthentication()
+    if err != nil {
+        return nil, err
+    }
+    repo.HTTPClient.Authentication = auth
+
+    switch format {
+    case "docker":
+        docker, err := dockerConfig(cmd)
+        if err != nil {
+            return nil, err
+        }
+        repo.Docker = docker
+        indexType := strings.ToUpper(dockerIndexType)
+        if indexType == "" {
+            indexType = "REGISTRY"
+            if strings.Contains(proxyRemoteURL, "registry-1.docker.io") {
+                indexType = "HUB"
+            }
+        }
+        if err := checkEnum("--docker-index-type", indexType, "REGISTRY", "HUB", "CUSTOM"); err != nil {
+            return nil, err
+        }
+        if indexType == "CUSTOM" && dockerIndexURL == "" {
+            return nil, fmt.Errorf("--docker-index-type CUSTOM needs --docker-index-url")
+        }
+        repo.DockerProxy = &client.DockerProxyConfig{
+            IndexType:                indexType,
+            IndexURL:                 dockerIndexURL,
+            CacheForeignLayers:       dockerCacheForeignLayers,
+            ForeignLayerURLWhitelist: dockerForeignLayerURLs,
+        }
+    case "maven2":
+        maven, err := mavenConfig("PERMISSIVE")
+        if err != nil {
+            return nil, err
+        }
+        repo.Maven = maven
+    case "npm":
+        repo.Npm = &client.NpmConfig{
+            RemoveNonCataloged: repoRemoveNonCataloged,
+            RemoveQuarantined:  repoRemoveQuarantined,
+        }
+    case "pypi":
+        repo.Pypi = &client.PypiConfig{RemoveQuarantined: repoRemoveQuarantined}
+    case "nuget":
+        version := strings.ToUpper(nugetVersion)
+        if err := checkEnum("--nuget-version", version, "V2", "V3"); err != nil {
+            return nil, err
+        }
+        repo.NugetProxy = &client.NugetProxyConfig{
+            QueryCacheItemMaxAge: nugetQueryCacheMaxAge,
+            NugetVersion:         version,
+        }
+    case "apt":
+        if aptDistribution == "" {
+            return nil, fmt.Errorf("apt repositories need --apt-distribution, e.g. bookworm")
+        }
+        repo.Apt = &client.AptConfig{Distribution: aptDistribution, Flat: aptFlat}
+    }
+    return repo, nil
+}
+
+func checkRemoteURL(name, remote string) error {
+    if u, err := url.Parse(remote); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+        return fmt.Errorf("%s %q is not an http(s) URL", name, remote)
+    }
+    return nil
+}
+
+func remoteAuthentication() (*client.HTTPAuthentication, error) {
+    return checkAuthentication(client.HTTPAuthentication{
+        Type:       proxyAuthType,
+        Username:   proxyRemoteUsername,
+        Password:   proxyRemotePassword,
+        NTLMHost:   proxyNTLMHost,
+        NTLMDomain: proxyNTLMDomain,
+    })
+}
+
+// checkAuthentication validates the remote authentication auth. Without a
+// username the proxy has none and nil is returned.
+func checkAuthentication(auth client.HTTPAuthentication) (*client.HTTPAuthentication, error) {
+    auth.Type = strings.ToLower(auth.Type)
+    if auth.Username == "" {
+        if auth.Password != "" || auth.NTLMHost != "" || auth.NTLMDomain != "" {
+            return nil, fmt.Errorf("--remote-password, --ntlm-host and --ntlm-domain need --remote-username")
+        }
+        return nil, nil
+    }
+    if err := checkEnum("--remote-auth-type", auth.Type, "username", "ntlm"); err != nil {
+        return nil, err
+    }
+    if auth.Type == "ntlm" && (auth.NTLMHost == "" || auth.NTLMDomain == "") {
+        return nil, fmt.Errorf("--remote-auth-type ntlm needs --ntlm-host and --ntlm-domain")
+    }
+    return &auth, nil
+}
+
+// stdinSecrets maps the flags of secrets that can be read from stdin to
+// their --*-stdin flag and value.
+func stdinSecrets() map[string]struct {
+    stdin *bool
+    value *string
+} {
+    return map[string]struct {
+        stdin *bool
+        value *string
+    }{
+        "remote-password": {&proxyPasswordStdin, &proxyRemotePassword},
+        "apt-passphrase":  {&aptPassStdin, &aptPassphrase},
+    }
+}
+
+// readStdinSecrets reads the secret whose --*-stdin flag is set, so it does
+// not end up in the shell history or the process list. A trailing newline
+// is dropped.
+func readStdinSecrets(cmd *cobra.Command) error {
+    read := ""
+    for flag, s := range stdinSecrets() {
+        if !*s.stdin {
+            continue
+        }
+        if cmd.Flags().Changed(flag) {
+            return fmt.Errorf("use either --%s or --%s-stdin", flag, flag)
+        }
+        if read != "" {
+            return fmt.Errorf("only one of --%s-stdin and --%s-stdin can be given", read, flag)
+        }
+        data, err := io.ReadAll(stdin)
+        if err != nil {
+            return fmt.Errorf("could not read --%s from stdin: %v", flag, err)
+        }
+        *s.value = strings.TrimRight(string(data), "\r\n")
+        read = flag
+    }
+    return nil
+}
+
+// secretGiven tells whether the secret of flag was given, directly or on
+// stdin.
+func secretGiven(cmd *cobra.Command, flag string) bool {
+    return cmd.Flags().Changed(flag) || *stdinSecrets()[flag].stdin
+}
+
+func dockerConfig(cmd *cobra.Command) (*client.DockerConfig, error) {
+    docker := &client.DockerConfig{
+        V1Enabled:      dockerV1Enabled,
+        ForceBasicAuth: dockerForceBasicAuth,
+        Subdomain:      dockerSubdomain,
+    }
+    for _, p := range []struct {
+        flag  string
+        value *int
+        dst   **int
+    }{
+        {"docker-http-port", &dockerHTTPPort, &docker.HTTPPort},
+        {"docker-https-port", &dockerHTTPSPort, &docker.HTTPSPort},
+    } {
+        if !cmd.Flags().Changed(p.flag) {
+            continue
+        }
+        if *p.value < 1 || *p.value > 65535 {
+            return nil, fmt.Errorf("--%s must be a port between 1 and 65535", p.flag)
+        }
+        *p.dst = p.value
+    }
+    return docker, nil
+}
+
+// mavenConfig builds the Maven settings; layoutDefault differs between
+// hosted (STRICT) and proxy (PERMISSIVE) repositories.
+func mavenConfig(layoutDefault string) (*client.MavenConfig, error) {
+    maven := &client.MavenConfig{
+        VersionPolicy:      strings.ToUpper(mavenVersionPolicy),
+        LayoutPolicy:       strings.ToUpper(mavenLayoutPolicy),
+        ContentDisposition: strings.ToUpper(mavenContentDisposition),
+    }
+    if maven.LayoutPolicy == "" {
+        maven.LayoutPolicy = layoutDefault
+    }
+    if err := checkEnum("--maven-version-policy", maven.VersionPolicy, "RELEASE", "SNAPSHOT", "MIXED"); err != nil {
+        return nil, err
+    }
+    if err := checkEnum("--maven-layout-policy", maven.LayoutPolicy, "STRICT", "PERMISSIVE"); err != nil {
+        return nil, err
+    }
+    if err := checkEnum("--maven-content-disposition", maven.ContentDisposition, "INLINE", "ATTACHMENT"); err != nil {
+        return nil, err
+    }
+    return maven, nil
+}
+
+// checkFormatFlags rejects format specific flags given for another format.
+func checkFormatFlags(cmd *cobra.Command, format string) error {
+    var err error
+    cmd.Flags().Visit(func(f *pflag.Flag) {
+        formats, ok := formatFlags[f.Name]
+        if ok && err == nil && !contains(formats, format) {
+            err = fmt.Errorf("--%s only applies to %s repositories", f.Name, strings.Join(formats, " and "))
+        }
+    })
+    return err
+}
+
+func checkEnum(flag, value string, allowed ...string) error {
+    if !contains(allowed, value) {
+        return fmt.Errorf("%s must be one of %s, got '%s'", flag, strings.Join(allowed, ", "), value)
+    }
+    return nil
+}
+
+func contains(list []string, s string) bool {
+    for _, item := range list {
+        if item == s {
+            return true
+        }
+    }
+    return false
+}
+
+// addStorageFlags adds the settings every repository type has.
+func addStorageFlags(cmd *cobra.Command) {
+    cmd.Flags().StringVar(&repoBlobStore, "blob-store", "default", "Blob store holding the repository's content")
+    cmd.Flags().BoolVar(&repoStrictContent, "strict-content-type", true, "Validate that content matches its MIME type")
+    cmd.Flags().BoolVar(&repoOnline, "online", true, "Accept incoming requests")
+}
+
+// addDockerFlags adds the connector settings every docker repository has.
+func addDockerFlags(cmd *cobra.Command) {
+    cmd.Flags().BoolVar(&dockerV1Enabled, "docker-v1-enabled", false, "Allow clients to use the V1 API")
+    cmd.Flags().BoolVar(&dockerForceBasicAuth, "docker-force-basic-auth", true, "Disallow anonymous pulls (no bearer token)")
+    cmd.Flags().IntVar(&dockerHTTPPort, "docker-http-port", 0, "HTTP connector port")
+    cmd.Flags().IntVar(&dockerHTTPSPort, "docker-https-port", 0, "HTTPS connector port")
+    cmd.Flags().StringVar(&dockerSubdomain, "docker-subdomain", "", "Subdomain to reach the repository on (Pro)")
+}
+
+// addFormatFlags adds the format specific settings of hosted and proxy
+// repositories.
+func addFormatFlags(cmd *cobra.Command) {
+    addDockerFlags(cmd)
+    cmd.Flags().StringVar(&mavenVersionPolicy, "maven-version-policy", "RELEASE", "RELEASE, SNAPSHOT or MIXED")
+    cmd.Flags().StringVar(&mavenLayoutPolicy, "maven-layout-policy", "", "STRICT or PERMISSIVE (default STRICT for hosted, PERMISSIVE for proxy)")
+    cmd.Flags().StringVar(&mavenContentDisposition, "maven-content-disposition", "INLINE", "INLINE or ATTACHMENT")
+    cmd.Flags().StringVar(&aptDistribution, "apt-distribution", "", "Distribution to fetch or host, e.g. bookworm")
+}
+
+func init() {
+    repoCmd.AddCommand(repoCreateProxyCmd)
+
+    addStorageFlags(repoCreateProxyCmd)
+    addFormatFlags(repoCreateProxyCmd)
+    repoCreateProxyCmd.Flags().StringSliceVar(&repoCleanupPolicies, "cleanup-policy", nil, "Cleanup policies to apply")
+    addProxyFlags(repoCreateProxyCmd)
+}
+
+// addProxyFlags adds the remote, caching and HTTP client settings of proxy
+// repositories, and the format specific settings only proxies have.
+func addProxyFlags(cmd *cobra.Command) {
+    f := cmd.Flags()
+    f.StringVar(&repoRoutingRule, "routing-rule", "", "Routing rule deciding which requests reach the remote")
+
+    f.StringVar(&proxyRemoteURL, "remote-url", "", "URL of the remote repository (required)")
+    f.IntVar(&proxyContentMaxAge, "content-max-age", 1440, "Minutes to cache artifacts")
+    f.IntVar(&proxyMetadataMaxAge, "metadata-max-age", 1440, "Minutes to cache metadata")
+    f.BoolVar(&proxyNegativeCache, "negative-cache", true, "Cache responses for content missing on the remote")
+    f.IntVar(&proxyNegativeCacheTTL, "negative-cache-ttl", 1440, "Minutes to cache missing content")
+    f.StringVar(&proxyRemoteUsername, "remote-username", "", "Username for the remote")
+    f.StringVar(&proxyRemotePassword, "remote-password", "", "Password for the remote (visible to other users in ps; prefer --remote-password-stdin)")
+    f.BoolVar(&proxyPasswordStdin, "remote-password-stdin", false, "Read the password for the remote from stdin")
+    f.StringVar(&proxyAuthType, "remote-auth-type", "username", "Remote authentication: username or ntlm")
+    f.StringVar(&proxyNTLMHost, "ntlm-host", "", "NTLM host")
+    f.StringVar(&proxyNTLMDomain, "ntlm-domain", "", "NTLM domain")
+    f.BoolVar(&proxyAutoBlock, "auto-block", true, "Block the remote for a while when it is unreachable")
+    f.BoolVar(&proxyBlocked, "blocked", false, "Create the repository with outbound requests blocked")
+    f.IntVar(&proxyHTTPTimeout, "http-timeout", 60, "Seconds to wait for the remote")
+    f.IntVar(&proxyHTTPRetries, "http-retries", 0, "Retries for failed requests to the remote")
+    f.StringVar(&proxyUserAgentSuffix, "user-agent-suffix", "", "Suffix appended to the User-Agent sent to the remote")
+    f.BoolVar(&proxyEnableCookies, "enable-cookies", false, "Accept cookies from the remote")
+    f.BoolVar(&proxyCircularRedirects, "enable-circular-redirects", false, "Follow redirects that loop back")
+    f.BoolVar(&proxyUseTrustStore, "use-trust-store", false, "Trust the remote's certificate from the Nexus trust store")
+
+    f.StringVar(&dockerIndexType, "docker-index-type", "", "REGISTRY, HUB or CUSTOM (default HUB for Docker Hub, else REGISTRY)")
+    f.StringVar(&dockerIndexURL, "docker-index-url", "", "Index URL for --docker-index-type CUSTOM")
+    f.BoolVar(&dockerCacheForeignLayers, "docker-cache-foreign-layers", false, "Cache foreign layers")
+    f.StringSliceVar(&dockerForeignLayerURLs, "docker-foreign-layer-url", nil, "Regular expressions of foreign layer URLs to allow")
+    f.BoolVar(&repoRemoveNonCataloged, "remove-non-cataloged", false, "Hide packages not in the firewall catalog (npm, Pro)")
+    f.BoolVar(&repoRemoveQuarantined, "remove-quarantined", false, "Hide quarantined versions (npm and pypi, Pro)")
+    f.StringVar(&nugetVersion, "nuget-version", "V3", "NuGet protocol of the remote: V2 or V3")
+    f.IntVar(&nugetQueryCacheMaxAge, "nuget-query-cache-max-age", 3600, "Seconds to cache query results")
+    f.BoolVar(&aptFlat, "apt-flat", false, "The remote is a flat repository")
+}
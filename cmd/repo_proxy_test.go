@@ -0,0 +1,179 @@
+package cmd
+
+import (
+    "bufio"
+    "strings"
+    "testing"
+    "nexuscli/internal/client"
+    "github.com/spf13/cobra"
+)
+
+// proxyCreateCmd returns a create-proxy command with fresh flags. Adding
+// them resets the variables behind them to their defaults.
+func proxyCreateCmd(t *testing.T, args ...string) *cobra.Command {
+    t.Helper()
+    cmd := &cobra.Command{Use: repoCreateProxyCmd.Use}
+    addStorageFlags(cmd)
+    addFormatFlags(cmd)
+    cmd.Flags().StringSliceVar(&repoCleanupPolicies, "cleanup-policy", nil, "Cleanup policies to apply")
+    addProxyFlags(cmd)
+    if err := cmd.ParseFlags(args); err != nil {
+        t.Fatal(err)
+    }
+    return cmd
+}
+
+func TestBuildProxyRepository(t *testing.T) {
+    tests := []struct {
+        name    string
+        format  string
+        args    []string
+        check   func(t *testing.T, repo *client.Repository)
+        wantErr string
+    }{
+        {
+            name:   "maven defaults",
+            format: "maven2",
+            args:   []string{"--remote-url", "https://repo1.maven.org/maven2/"},
+            check: func(t *testing.T, repo *client.Repository) {
+                if repo.Proxy.RemoteURL != "https://repo1.maven.org/maven2/" || repo.Proxy.ContentMaxAge != 1440 {
+                    t.Errorf("proxy settings %+v", repo.Proxy)
+                }
+                if repo.Maven.LayoutPolicy != "PERMISSIVE" || repo.Maven.VersionPolicy != "RELEASE" {
+                    t.Errorf("maven settings %+v", repo.Maven)
+                }
+                if repo.Storage.BlobStoreName != "default" || !*repo.Online || !repo.NegativeCache.Enabled {
+                    t.Errorf("storage %+v, online %v, negative cache %+v", repo.Storage, *repo.Online, repo.NegativeCache)
+                }
+                if repo.HTTPClient.Authentication != nil || repo.HTTPClient.Connection.Timeout != nil {
+                    t.Errorf("unrequested HTTP client settings %+v", repo.HTTPClient)
+                }
+            },
+        },
+        {
+            name:   "caching, HTTP client and remote authentication",
+            format: "npm",
+            args: []string{
+                "--remote-url", "https://registry.npmjs.org", "--content-max-age", "-1", "--negative-cache=false",
+                "--http-timeout", "30", "--remote-username", "bot", "--remote-password", "pw",
+                "--routing-rule", "no-snapshots", "--cleanup-policy", "weekly", "--remove-quarantined",
+            },
+            check: func(t *testing.T, repo *client.Repository) {
+                if repo.Proxy.ContentMaxAge != -1 || repo.NegativeCache.Enabled {
+                    t.Errorf("proxy %+v, negative cache %+v", repo.Proxy, repo.NegativeCache)
+                }
+                if timeout := repo.HTTPClient.Connection.Timeout; timeout == nil || *timeout != 30 {
+                    t.Errorf("timeout %v, want 30", timeout)
+                }
+                auth := repo.HTTPClient.Authentication
+                if auth == nil || auth.Type != "username" || auth.Username != "bot" || auth.Password != "pw" {
+                    t.Errorf("authentication %+v", auth)
+                }
+                if repo.RoutingRuleName != "no-snapshots" || repo.Cleanup == nil || repo.Cleanup.PolicyNames[0] != "weekly" {
+                    t.Errorf("routing rule %q, cleanup %+v", repo.RoutingRuleName, repo.Cleanup)
+                }
+                if repo.Npm == nil || !repo.Npm.RemoveQuarantined {
+                    t.Errorf("npm settings %+v", repo.Npm)
+                }
+            },
+        },
+        {
+            name:   "docker hub",
+            format: "docker",
+            args:   []string{"--remote-url", "https://registry-1.docker.io", "--docker-http-port", "8082"},
+            check: func(t *testing.T, repo *client.Repository) {
+                if repo.DockerProxy.IndexType != "HUB" {
+                    t.Errorf("index type %s, want HUB", repo.DockerProxy.IndexType)
+                }
+                if port := repo.Docker.HTTPPort; port == nil || *port != 8082 || repo.Docker.HTTPSPort != nil {
+                    t.Errorf("docker settings %+v", repo.Docker)
+                }
+            },
+        },
+        {
+            name:   "other docker registry",
+            format: "docker",
+            args:   []string{"--remote-url", "https://ghcr.io"},
+            check: func(t *testing.T, repo *client.Repository) {
+                if repo.DockerProxy.IndexType != "REGISTRY" {
+                    t.Errorf("index type %s, want REGISTRY", repo.DockerProxy.IndexType)
+                }
+            },
+        },
+        {
+            name:   "nuget",
+            format: "nuget",
+            args:   []string{"--remote-url", "https://api.nuget.org/v3/index.json", "--nuget-version", "v3"},
+            check: func(t *testing.T, repo *client.Repository) {
+                if repo.NugetProxy.NugetVersion != "V3" {
+                    t.Errorf("nuget version %s, want V3", repo.NugetProxy.NugetVersion)
+                }
+            },
+        },
+        {name: "unsupported format", format: "bower", args: []string{"--remote-url", "https://example.com"}, wantErr: "unsupported proxy format 'bower'"},
+        {name: "missing remote URL", format: "npm", wantErr: "--remote-url is required"},
+        {name: "remote URL not http", format: "npm", args: []string{"--remote-url", "ftp://example.com"}, wantErr: "is not an http(s) URL"},
+        {name: "flag of another format", format: "npm", args: []string{"--remote-url", "https://example.com", "--maven-layout-policy", "STRICT"}, wantErr: "--maven-layout-policy only applies to maven2 repositories"},
+        {name: "invalid layout policy", format: "maven2", args: []string{"--remote-url", "https://example.com", "--maven-layout-policy", "loose"}, wantErr: "--maven-layout-policy must be one of STRICT, PERMISSIVE, got 'LOOSE'"},
+        {name: "docker port out of range", format: "docker", args: []string{"--remote-url", "https://example.com", "--docker-https-port", "70000"}, wantErr: "--docker-https-port must be a port"},
+        {name: "custom docker index without URL", format: "docker", args: []string{"--remote-url", "https://example.com", "--docker-index-type", "custom"}, wantErr: "--docker-index-type CUSTOM needs --docker-index-url"},
+        {name: "apt without distribution", format: "apt", args: []string{"--remote-url", "https://deb.debian.org/debian"}, wantErr: "apt repositories need --apt-distribution"},
+        {name: "password without username", format: "npm", args: []string{"--remote-url", "https://example.com", "--remote-password", "pw"}, wantErr: "need --remote-username"},
+        {name: "ntlm without host", format: "npm", args: []string{"--remote-url", "https://example.com", "--remote-username", "bot", "--remote-auth-type", "NTLM"}, wantErr: "--remote-auth-type ntlm needs --ntlm-host and --ntlm-domain"},
+        {name: "unknown auth type", format: "npm", args: []string{"--remote-url", "https://example.com", "--remote-username", "bot", "--remote-auth-type", "kerberos"}, wantErr: "--remote-auth-type must be one of"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            cmd := proxyCreateCmd(t, tt.args...)
+            repo, err := buildProxyRepository(cmd, tt.format, "proxy")
+            if tt.wantErr != "" {
+                if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                    t.Fatalf("got error %v, want %q", err, tt.wantErr)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatal(err)
+            }
+            if repo.Name != "proxy" || repo.Format != tt.format {
+                t.Errorf("got %s repository %s", repo.Format, repo.Name)
+            }
+            tt.check(t, repo)
+        })
+    }
+}
+
+func TestReadStdinSecrets(t *testing.T) {
+    tests := []struct {
+        name    string
+        args    []string
+        want    string
+        wantErr string
+    }{
+        {name: "from stdin", args: []string{"--remote-password-stdin"}, want: "s3cret"},
+        {name: "from the flag", args: []string{"--remote-password", "pw"}, want: "pw"},
+        {name: "both", args: []string{"--remote-password", "pw", "--remote-password-stdin"}, wantErr: "use either --remote-password or --remote-password-stdin"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            cmd := proxyCreateCmd(t, tt.args...)
+            stdin = bufio.NewReader(strings.NewReader("s3cret\n"))
+
+            err := readStdinSecrets(cmd)
+            if tt.wantErr != "" {
+                if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                    t.Fatalf("got error %v, want %q", err, tt.wantErr)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatal(err)
+            }
+            if proxyRemotePassword != tt.want {
+                t.Errorf("password %q, want %q", proxyRemotePassword, tt.want)
+            }
+        })
+    }
+}
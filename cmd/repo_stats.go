@@ -0,0 +1,446 @@
+package cmd
+
+import (
+    "context"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+    "nexuscli/internal/client"
+    "nexuscli/internal/output"
+    "nexuscli/internal/terminal"
+    "github.com/spf13/cobra"
+    "gopkg.in/yaml.v3"
+)
+
+var (
+    statsWorkers int
+    statsTop     int
+    statsMaxAge  time.Duration
+    statsRefresh bool
+    statsSortBy  string
+)
+
+// repoStats is what repo stats reports for one repository, and what its
+// cache keeps.
+type repoStats struct {
+    Repository string         `json:"repository" yaml:"repository"`
+    Format     string         `json:"format" yaml:"format"`
+    Type       string         `json:"type" yaml:"type"`
+    Components int64          `json:"components" yaml:"components"`
+    Assets     int64          `json:"assets" yaml:"assets"`
+    Bytes      int64          `json:"bytes" yaml:"bytes"`
+    Largest    []largestAsset `json:"largestAssets" yaml:"largestAssets"`
+    Newest     *time.Time     `json:"newestUpload,omitempty" yaml:"newestUpload,omitempty"`
+    Oldest     *time.Time     `json:"oldestUpload,omitempty" yaml:"oldestUpload,omitempty"`
+    Collected  time.Time      `json:"collected" yaml:"collected"`
+}
+
+type largestAsset struct {
+    Repository string `json:"-" yaml:"-"`
+    Path       string `json:"path" yaml:"path"`
+    Bytes      int64  `json:"bytes" yaml:"bytes"`
+}
+
+// statsCache maps server URLs to the statistics of their repositories.
+type statsCache map[string]map[string]repoStats
+
+var repoStatsCmd = &cobra.Command{
+    Use:   "stats [repo_name...]",
+    Short: "Show the size and component count of repositories",
+    Long: `Walk the assets and components of repositories and report their count,
+total size, largest assets and newest and oldest upload, largest first.
+
+Without names every hosted and proxy repository is scanned; --format and
+--type narrow the selection. Group repositories store nothing themselves
+and are skipped. Repositories are scanned --workers at a time.
+
+Results are cached in the user cache directory; a re-run only rescans the
+repositories whose statistics are older than --max-age. -o csv writes one
+line per repository.`,
+    Example: `  nexuscli repo stats
+  nexuscli repo stats --format docker --top 10
+  nexuscli repo stats maven-releases maven-snapshots --refresh -o json
+  nexuscli repo stats -o csv > sizes.csv`,
+    Run: func(cmd *cobra.Command, args []string) {
+        ctx := cmd.Context()
+        if statsWorkers < 1 || statsTop < 0 {
+            fmt.Fprintln(os.Stderr, "Error: --workers must be at least 1 and --top must not be negative.")
+            os.Exit(ExitUsage)
+        }
+        if err := checkEnum("--sort-by", strings.ToLower(statsSortBy), "size", "assets", "components", "name"); err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+
+        var repos []client.Repository
+        var err error
+        if len(args) == 0 && selectFormat == "" && selectType == "" {
+            repos, err = nexusClient.ListRepositories(ctx, client.ListOptions{}).All()
+        } else {
+            repos, err = selectRepositories(ctx, args, []string{"hosted", "proxy"})
+        }
+        if err != nil {
+            fail(err, "Error selecting repositories")
+        }
+        scanned := []client.Repository{}
+        for _, r := range repos {
+            if r.Type != "group" {
+                scanned = append(scanned, r)
+            }
+        }
+
+        cachePath := statsCachePath()
+        cache := loadStatsCache(cachePath)
+        server := nexusClient.BaseURL()
+        if cache[server] == nil {
+            cache[server] = map[string]repoStats{}
+        }
+
+        stats := []repoStats{}
+        stale := []client.Repository{}
+        for _, r := range scanned {
+            cached, ok := cache[server][r.Name]
+            if ok && !statsRefresh && cached.fresh(statsMaxAge, statsTop) {
+                stats = append(stats, cached)
+                continue
+            }
+            stale = append(stale, r)
+        }
+        if len(stats) > 0 {
+            fmt.Fprintf(os.Stderr, "%d of %d repositories from the cache (scanned less than %s ago); use --refresh to rescan.\n",
+                len(stats), len(scanned), statsMaxAge)
+        }
+
+        fresh, firstErr := collectStats(ctx, stale)
+        for _, s := range fresh {
+            cache[server][s.Repository] = s
+            stats = append(stats, s)
+        }
+        if cachePath != "" && len(fresh) > 0 {
+            if err := saveStatsCache(cachePath, cache); err != nil {
+                fmt.Fprintf(os.Stderr, "Warning: could not save the statistics cache: %v\n", err)
+            }
+        }
+        if ctx.Err() != nil {
+            fail(ctx.Err(), "Stopped with %d of %d repositories scanned", len(fresh), len(stale))
+        }
+
+        sortStats(stats, strings.ToLower(statsSortBy))
+        for i := range stats {
+            if len(stats[i].Largest) > statsTop {
+                stats[i].Largest = stats[i].Largest[:statsTop]
+            }
+        }
+        printStats(stats)
+        if firstErr != nil {
+            os.Exit(exitCode(firstErr))
+        }
+    },
+}
+
+// collectStats scans repos with statsWorkers workers. Repositories that
+// fail are reported and left out; the first error is returned with the
+// statistics of the others.
+func collectStats(ctx context.Context, repos []client.Repository) ([]repoStats, error) {
+    var mu sync.Mutex
+    var wg sync.WaitGroup
+    var firstErr error
+    results := []repoStats{}
+    done := 0
+    progress := terminal.IsTerminal(int(os.Stderr.Fd())) && len(repos) > 1
+
+    jobs := make(chan client.Repository)
+    for i := 0; i < statsWorkers; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for repo := range jobs {
+                s, err := scanRepository(ctx, repo)
+                mu.Lock()
+                switch {
+                case err == nil:
+                    results = append(results, *s)
+                case ctx.Err() == nil && !errors.Is(err, client.ErrDryRun):
+                    fmt.Fprintf(os.Stderr, "Error: %s: %v\n", repo.Name, err)
+                    if firstErr == nil {
+                        firstErr = err
+                    }
+                }
+                done++
+                if progress {
+                    fmt.Fprintf(os.Stderr, "\rScanned %d of %d repositories", done, len(repos))
+                }
+                mu.Unlock()
+            }
+        }()
+    }
+
+feed:
+    for _, r := range repos {
+        select {
+        case jobs <- r:
+        case <-ctx.Done():
+            break feed
+        }
+    }
+    close(jobs)
+    wg.Wait()
+    if progress {
+        fmt.Fprintln(os.Stderr)
+    }
+    return results, firstErr
+}
+
+// scanRepository walks the assets and then the components of repo.
+func scanRepository(ctx context.Context, repo client.Repository) (*repoStats, error) {
+    s := &repoStats{Repository: repo.Name, Format: repo.Format, Type: repo.Type, Largest: []largestAsset{}}
+
+    assets := nexusClient.ListAssets(ctx, repo.Name, client.ListOptions{PageSize: listPageSize})
+    for assets.Next() {
+        s.addAsset(assets.Item())
+    }
+    if err := assets.Err(); err != nil {
+        return nil, err
+    }
+    components := nexusClient.ListComponents(ctx, repo.Name, client.ListOptions{PageSize: listPageSize})
+    for components.Next() {
+        s.Components++
+    }
+    if err := components.Err(); err != nil {
+        return nil, err
+    }
+    s.Collected = time.Now().UTC()
+    return s, nil
+}
+
+// fresh reports whether cached statistics can be reported as they are:
+// collected less than maxAge ago and holding enough of the largest assets.
+func (s repoStats) fresh(maxAge time.Duration, top int) bool {
+    return time.Since(s.Collected) < maxAge && len(s.Largest) >= min(top, int(s.Assets))
+}
+
+func (s *repoStats) addAsset(a client.Asset) {
+    s.Assets++
+    s.Bytes += a.FileSize
+
+    uploaded := a.BlobCreated
+    if uploaded == "" {
+        uploaded = a.LastModified
+    }
+    if t, err := time.Parse(time.RFC3339, uploaded); err == nil {
+        t = t.UTC()
+        if s.Newest == nil || t.After(*s.Newest) {
+            s.Newest = &t
+        }
+        if s.Oldest == nil || t.Before(*s.Oldest) {
+            s.Oldest = &t
+        }
+    }
+
+    // keep the statsTop largest, largest first
+    if len(s.Largest) == statsTop && (statsTop == 0 || a.FileSize <= s.Largest[len(s.Largest)-1].Bytes) {
+        return
+    }
+    i := sort.Search(len(s.Largest), func(i int) bool { return s.Largest[i].Bytes < a.FileSize })
+    s.Largest = append(s.Largest, largestAsset{})
+    copy(s.Largest[i+1:], s.Largest[i:])
+    s.Largest[i] = largestAsset{Path: a.Path, Bytes: a.FileSize}
+    if len(s.Largest) > statsTop {
+        s.Largest = s.Largest[:statsTop]
+    }
+}
+
+func sortStats(stats []repoStats, by string) {
+    sort.SliceStable(stats, func(i, j int) bool {
+        a, b := stats[i], stats[j]
+        switch by {
+        case "name":
+            return a.Repository < b.Repository
+        case "assets":
+            return a.Assets > b.Assets
+        case "components":
+            return a.Components > b.Components
+        }
+        return a.Bytes > b.Bytes
+    })
+}
+
+func printStats(stats []repoStats) {
+    switch strings.ToLower(outputFormat) {
+    case "json":
+        data, _ := json.MarshalIndent(stats, "", "  ")
+        fmt.Println(string(data))
+        return
+    case "yaml", "yml":
+        data, _ := yaml.Marshal(stats)
+        fmt.Print(string(data))
+        return
+    case "csv":
+        if err := writeStatsCSV(os.Stdout, stats); err != nil {
+            fail(err, "Error writing CSV")
+        }
+        return
+    }
+
+    if len(stats) == 0 {
+        fmt.Println("No repositories found.")
+        return
+    }
+    items := []map[string]interface{}{}
+    total := repoStats{Repository: "TOTAL"}
+    largest := []largestAsset{}
+    for _, s := range stats {
+        items = append(items, statsRow(s))
+        total.Components += s.Components
+        total.Assets += s.Assets
+        total.Bytes += s.Bytes
+        for _, a := range s.Largest {
+            a.Repository = s.Repository
+            largest = append(largest, a)
+        }
+    }
+    if len(stats) > 1 {
+        items = append(items, statsRow(total))
+    }
+    headers := []string{"REPOSITORY", "FORMAT", "TYPE", "COMPONENTS", "ASSETS", "SIZE", "NEWEST UPLOAD", "OLDEST UPLOAD"}
+    output.Render(items, "table", headers, nil)
+
+    sort.SliceStable(largest, func(i, j int) bool { return largest[i].Bytes > largest[j].Bytes })
+    if len(largest) > statsTop {
+        largest = largest[:statsTop]
+    }
+    if len(largest) == 0 {
+        return
+    }
+    fmt.Println()
+    rows := []map[string]interface{}{}
+    for _, a := range largest {
+        rows = append(rows, map[string]interface{}{"LARGEST ASSETS": a.Path, "REPOSITORY": a.Repository, "SIZE": humanBytes(a.Bytes)})
+    }
+    output.Render(rows, "table", []string{"LARGEST ASSETS", "REPOSITORY", "SIZE"}, nil)
+}
+
+// writeStatsCSV writes one line per repository, with the largest asset.
+func writeStatsCSV(out io.Writer, stats []repoStats) error {
+    w := csv.NewWriter(out)
+    w.Write([]string{"repository", "format", "type", "components", "assets", "bytes",
+        "newest_upload", "oldest_upload", "largest_asset", "largest_asset_bytes"})
+    for _, s := range stats {
+        largest, largestBytes := "", ""
+        if len(s.Largest) > 0 {
+            largest, largestBytes = s.Largest[0].Path, strconv.FormatInt(s.Largest[0].Bytes, 10)
+        }
+        w.Write([]string{s.Repository, s.Format, s.Type,
+            strconv.FormatInt(s.Components, 10), strconv.FormatInt(s.Assets, 10), strconv.FormatInt(s.Bytes, 10),
+            formatTime(s.Newest, time.RFC3339), formatTime(s.Oldest, time.RFC3339), largest, largestBytes})
+    }
+    w.Flush()
+    return w.Error()
+}
+
+func statsRow(s repoStats) map[string]interface{} {
+    return map[string]interface{}{
+        "REPOSITORY":    s.Repository,
+        "FORMAT":        s.Format,
+        "TYPE":          s.Type,
+        "COMPONENTS":    s.Components,
+        "ASSETS":        s.Assets,
+        "SIZE":          humanBytes(s.Bytes),
+        "NEWEST UPLOAD": formatTime(s.Newest, "2006-01-02 15:04"),
+        "OLDEST UPLOAD": formatTime(s.Oldest, "2006-01-02 15:04"),
+    }
+}
+
+func formatTime(t *time.Time, layout string) string {
+    if t == nil {
+        return ""
+    }
+    return t.Format(layout)
+}
+
+// humanBytes formats a size with binary units, e.g. 1.5 GiB.
+func humanBytes(n int64) string {
+    if n < 1024 {
+        return fmt.Sprintf("%d B", n)
+    }
+    value, unit := float64(n)/1024, 0
+    for value >= 1024 && unit < 4 {
+        value /= 1024
+        unit++
+    }
+    return fmt.Sprintf("%.1f %ciB", value, "KMGTP"[unit])
+}
+
+// statsCachePath returns the cache file, or "" when the user has no cache
+// directory.
+func statsCachePath() string {
+    dir, err := os.UserCacheDir()
+    if err != nil {
+        return ""
+    }
+    return filepath.Join(dir, "nexuscli", "repo-stats.json")
+}
+
+// loadStatsCache reads the cache; a missing or damaged cache is empty.
+func loadStatsCache(path string) statsCache {
+    cache := statsCache{}
+    if path == "" {
+        return cache
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return cache
+    }
+    if err := json.Unmarshal(data, &cache); err != nil {
+        return statsCache{}
+    }
+    return cache
+}
+
+// saveStatsCache replaces the cache atomically, so concurrent runs never
+// read half a file.
+func saveStatsCache(path string, cache statsCache) error {
+    data, err := json.Marshal(cache)
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+        return err
+    }
+    tmp, err := os.CreateTemp(filepath.Dir(path), ".repo-stats.*.tmp")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), path)
+}
+
+func init() {
+    repoCmd.AddCommand(repoStatsCmd)
+
+    f := repoStatsCmd.Flags()
+    f.StringVar(&selectFormat, "format", "", "Only scan repositories of this format")
+    f.StringVar(&selectType, "type", "", "Only scan repositories of this type: hosted or proxy")
+    f.IntVar(&statsWorkers, "workers", 4, "Repositories scanned at once")
+    f.IntVar(&statsTop, "top", 5, "Number of largest assets to report")
+    f.DurationVar(&statsMaxAge, "max-age", time.Hour, "Rescan repositories whose cached statistics are older than this")
+    f.BoolVar(&statsRefresh, "refresh", false, "Ignore the cache and rescan every repository")
+    f.StringVar(&statsSortBy, "sort-by", "size", "Sort by size, assets, components or name")
+    f.IntVar(&listPageSize, "page-size", 0, "Items to request per page (0 = server default)")
+}
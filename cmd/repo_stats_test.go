@@ -0,0 +1,275 @@
+package cmd
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "testing"
+    "time"
+    "nexuscli/internal/client"
+)
+
+// setStatsTop sets --top for the duration of a test.
+func setStatsTop(t *testing.T, top int) {
+    old := statsTop
+    statsTop = top
+    t.Cleanup(func() { statsTop = old })
+}
+
+func TestAddAsset(t *testing.T) {
+    tests := []struct {
+        name        string
+        top         int
+        assets      []client.Asset
+        wantLargest []int64
+        wantNewest  string
+        wantOldest  string
+    }{
+        {
+            name: "keeps the largest, largest first",
+            top:  2,
+            assets: []client.Asset{
+                {Path: "a", FileSize: 5}, {Path: "b", FileSize: 1}, {Path: "c", FileSize: 9},
+                {Path: "d", FileSize: 5}, {Path: "e", FileSize: 7},
+            },
+            wantLargest: []int64{9, 7},
+        },
+        {
+            name:        "top 0",
+            top:         0,
+            assets:      []client.Asset{{Path: "a", FileSize: 5}},
+            wantLargest: []int64{},
+        },
+        {
+            name: "upload dates",
+            top:  5,
+            assets: []client.Asset{
+                {Path: "a", BlobCreated: "2024-03-01T10:00:00+01:00"},
+                {Path: "b", LastModified: "2023-01-01T00:00:00Z"},
+                {Path: "c", BlobCreated: "2025-06-30T12:00:00Z"},
+                {Path: "d", BlobCreated: "yesterday"},
+            },
+            wantLargest: []int64{0, 0, 0, 0},
+            wantNewest:  "2025-06-30T12:00:00Z",
+            wantOldest:  "2023-01-01T00:00:00Z",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            setStatsTop(t, tt.top)
+            s := &repoStats{Largest: []largestAsset{}}
+            var total int64
+            for _, a := range tt.assets {
+                s.addAsset(a)
+                total += a.FileSize
+            }
+            if s.Assets != int64(len(tt.assets)) || s.Bytes != total {
+                t.Errorf("counted %d assets of %d bytes, want %d of %d", s.Assets, s.Bytes, len(tt.assets), total)
+            }
+            largest := []int64{}
+            for _, a := range s.Largest {
+                largest = append(largest, a.Bytes)
+            }
+            if !reflect.DeepEqual(largest, tt.wantLargest) {
+                t.Errorf("largest = %v, want %v", largest, tt.wantLargest)
+            }
+            if got := formatTime(s.Newest, time.RFC3339); got != tt.wantNewest {
+                t.Errorf("newest = %q, want %q", got, tt.wantNewest)
+            }
+            if got := formatTime(s.Oldest, time.RFC3339); got != tt.wantOldest {
+                t.Errorf("oldest = %q, want %q", got, tt.wantOldest)
+            }
+        })
+    }
+}
+
+func TestStatsFresh(t *testing.T) {
+    recent := time.Now().Add(-time.Minute)
+    largest := []largestAsset{{Bytes: 3}, {Bytes: 2}}
+    tests := []struct {
+        name  string
+        stats repoStats
+        top   int
+        want  bool
+    }{
+        {"recent", repoStats{Collected: recent, Assets: 10, Largest: largest}, 2, true},
+        {"too old", repoStats{Collected: time.Now().Add(-2 * time.Hour), Assets: 10, Largest: largest}, 2, false},
+        {"more largest assets asked for", repoStats{Collected: recent, Assets: 10, Largest: largest}, 5, false},
+        {"repository has fewer assets than --top", repoStats{Collected: recent, Assets: 2, Largest: largest}, 5, true},
+        {"empty repository", repoStats{Collected: recent}, 5, true},
+    }
+    for _, tt := range tests {
+        if got := tt.stats.fresh(time.Hour, tt.top); got != tt.want {
+            t.Errorf("%s: fresh() = %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestWriteStatsCSV(t *testing.T) {
+    newest := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+    stats := []repoStats{
+        {
+            Repository: "maven-releases", Format: "maven2", Type: "hosted",
+            Components: 2, Assets: 4, Bytes: 1536,
+            Largest: []largestAsset{{Path: "com/acme/app, \"final\".jar", Bytes: 1024}},
+            Newest:  &newest, Oldest: &newest,
+        },
+        {Repository: "empty", Format: "npm", Type: "proxy", Largest: []largestAsset{}},
+    }
+    var out bytes.Buffer
+    if err := writeStatsCSV(&out, stats); err != nil {
+        t.Fatal(err)
+    }
+    want := "repository,format,type,components,assets,bytes,newest_upload,oldest_upload,largest_asset,largest_asset_bytes\n" +
+        "maven-releases,maven2,hosted,2,4,1536,2025-06-30T12:00:00Z,2025-06-30T12:00:00Z,\"com/acme/app, \"\"final\"\".jar\",1024\n" +
+        "empty,npm,proxy,0,0,0,,,,\n"
+    if out.String() != want {
+        t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+    }
+
+    if err := writeStatsCSV(failingWriter{}, stats); err == nil {
+        t.Error("write error not reported")
+    }
+}
+
+// failingWriter fails every write, like stdout closed by the reader.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+    return 0, errors.New("broken pipe")
+}
+
+func TestStatsCache(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "nexuscli", "repo-stats.json")
+    if got := loadStatsCache(path); len(got) != 0 {
+        t.Errorf("missing cache = %v, want empty", got)
+    }
+
+    collected := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+    cache := statsCache{"https://nexus": {"npm": {Repository: "npm", Assets: 3, Largest: []largestAsset{{Path: "a", Bytes: 1}}, Collected: collected}}}
+    if err := saveStatsCache(path, cache); err != nil {
+        t.Fatal(err)
+    }
+    if got := loadStatsCache(path); !reflect.DeepEqual(got, cache) {
+        t.Errorf("loaded %v, want %v", got, cache)
+    }
+    if leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".repo-stats.*")); len(leftovers) > 0 {
+        t.Errorf("temporary files left behind: %v", leftovers)
+    }
+
+    if err := os.WriteFile(path, []byte(`{"https://nexus": [`), 0600); err != nil {
+        t.Fatal(err)
+    }
+    if got := loadStatsCache(path); len(got) != 0 {
+        t.Errorf("damaged cache = %v, want empty", got)
+    }
+    if got := loadStatsCache(""); len(got) != 0 {
+        t.Errorf("no cache dir = %v, want empty", got)
+    }
+}
+
+func TestSortStats(t *testing.T) {
+    stats := []repoStats{
+        {Repository: "b", Bytes: 1, Assets: 30, Components: 2},
+        {Repository: "c", Bytes: 3, Assets: 10, Components: 1},
+        {Repository: "a", Bytes: 2, Assets: 20, Components: 3},
+    }
+    tests := []struct {
+        by   string
+        want []string
+    }{
+        {"size", []string{"c", "a", "b"}},
+        {"assets", []string{"b", "a", "c"}},
+        {"components", []string{"a", "b", "c"}},
+        {"name", []string{"a", "b", "c"}},
+    }
+    for _, tt := range tests {
+        sorted := append([]repoStats{}, stats...)
+        sortStats(sorted, tt.by)
+        var got []string
+        for _, s := range sorted {
+            got = append(got, s.Repository)
+        }
+        if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("sort by %s = %v, want %v", tt.by, got, tt.want)
+        }
+    }
+}
+
+func TestHumanBytes(t *testing.T) {
+    tests := []struct {
+        n    int64
+        want string
+    }{
+        {0, "0 B"},
+        {1023, "1023 B"},
+        {1024, "1.0 KiB"},
+        {1536, "1.5 KiB"},
+        {5 << 30, "5.0 GiB"},
+        {3 << 50, "3.0 PiB"},
+        {2 << 60, "2048.0 PiB"},
+    }
+    for _, tt := range tests {
+        if got := humanBytes(tt.n); got != tt.want {
+            t.Errorf("humanBytes(%d) = %q, want %q", tt.n, got, tt.want)
+        }
+    }
+}
+
+func TestCollectStats(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        repo := r.URL.Query().Get("repository")
+        if repo == "broken" {
+            http.Error(w, `{"message":"boom"}`, http.StatusInternalServerError)
+            return
+        }
+        switch {
+        case r.URL.Path == "/service/rest/v1/assets" && r.URL.Query().Get("continuationToken") == "":
+            w.Write([]byte(`{"items":[{"path":"a","fileSize":10},{"path":"b","fileSize":30}],"continuationToken":"next"}`))
+        case r.URL.Path == "/service/rest/v1/assets":
+            w.Write([]byte(`{"items":[{"path":"c","fileSize":20}]}`))
+        case r.URL.Path == "/service/rest/v1/components":
+            w.Write([]byte(`{"items":[{"name":"x"},{"name":"y"}]}`))
+        default:
+            http.NotFound(w, r)
+        }
+    }))
+    defer srv.Close()
+
+    c, err := client.New(srv.URL, client.WithRetryPolicy(client.RetryPolicy{}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    oldClient, oldWorkers := nexusClient, statsWorkers
+    nexusClient, statsWorkers = c, 2
+    defer func() { nexusClient, statsWorkers = oldClient, oldWorkers }()
+    setStatsTop(t, 2)
+
+    repos := []client.Repository{{Name: "one"}, {Name: "broken"}, {Name: "two"}}
+    stats, err := collectStats(context.Background(), repos)
+    if !client.IsServerError(err) {
+        t.Errorf("got error %v, want the 500 of the broken repository", err)
+    }
+    sort.Slice(stats, func(i, j int) bool { return stats[i].Repository < stats[j].Repository })
+    if len(stats) != 2 || stats[0].Repository != "one" || stats[1].Repository != "two" {
+        t.Fatalf("got statistics for %v, want one and two", stats)
+    }
+    for _, s := range stats {
+        if s.Assets != 3 || s.Bytes != 60 || s.Components != 2 {
+            t.Errorf("%s: %d assets, %d bytes, %d components, want 3, 60, 2", s.Repository, s.Assets, s.Bytes, s.Components)
+        }
+        if len(s.Largest) != 2 || s.Largest[0].Path != "b" || s.Largest[1].Path != "c" {
+            t.Errorf("%s: largest %v, want b and c", s.Repository, s.Largest)
+        }
+        if s.Collected.IsZero() {
+            t.Errorf("%s: no collection time", s.Repository)
+        }
+    }
+}
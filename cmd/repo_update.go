@@ -0,0 +1,758 @@
+package cmd
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "reflect"
+    "strings"
+    "nexuscli/internal/client"
+    "github.com/spf13/cobra"
+    "github.com/spf13/pflag"
+    "gopkg.in/yaml.v3"
+)
+
+// typeFlags lists the flags of repo update that only apply to some
+// repository types.
+var typeFlags = map[string][]string{
+    "write-policy":                {"hosted"},
+    "proprietary-components":      {"hosted"},
+    "apt-keypair-file":            {"hosted"},
+    "apt-passphrase":              {"hosted"},
+    "apt-passphrase-stdin":        {"hosted"},
+    "yum-repodata-depth":          {"hosted"},
+    "yum-deploy-policy":           {"hosted"},
+    "routing-rule":                {"proxy"},
+    "remote-url":                  {"proxy"},
+    "content-max-age":             {"proxy"},
+    "metadata-max-age":            {"proxy"},
+    "negative-cache":              {"proxy"},
+    "negative-cache-ttl":          {"proxy"},
+    "remote-username":             {"proxy"},
+    "remote-password":             {"proxy"},
+    "remote-password-stdin":       {"proxy"},
+    "remote-auth-type":            {"proxy"},
+    "ntlm-host":                   {"proxy"},
+    "ntlm-domain":                 {"proxy"},
+    "auto-block":                  {"proxy"},
+    "blocked":                     {"proxy"},
+    "http-timeout":                {"proxy"},
+    "http-retries":                {"proxy"},
+    "user-agent-suffix":           {"proxy"},
+    "enable-cookies":              {"proxy"},
+    "enable-circular-redirects":   {"proxy"},
+    "use-trust-store":             {"proxy"},
+    "docker-index-type":           {"proxy"},
+    "docker-index-url":            {"proxy"},
+    "docker-cache-foreign-layers": {"proxy"},
+    "docker-foreign-layer-url":    {"proxy"},
+    "remove-non-cataloged":        {"proxy"},
+    "remove-quarantined":          {"proxy"},
+    "nuget-version":               {"proxy"},
+    "nuget-query-cache-max-age":   {"proxy"},
+    "apt-flat":                    {"proxy"},
+    "cleanup-policy":              {"hosted", "proxy"},
+    "maven-version-policy":        {"hosted", "proxy"},
+    "maven-layout-policy":         {"hosted", "proxy"},
+    "maven-content-disposition":   {"hosted", "proxy"},
+    "apt-distribution":            {"hosted", "proxy"},
+}
+
+var repoUpdateCmd = &cobra.Command{
+    Use:   "update <repo_name>",
+    Short: "Change settings of an existing repository",
+    Long: `Change settings of a repository. The current configuration is read, the
+flags given are applied to it and the result is written back, so settings
+not named on the command line keep their values.
+
+The flags are those of create and create-proxy. Flags for another format
+or repository type are rejected; --cleanup-policy "" removes all cleanup
+policies. The blob store cannot be changed. Use "repo group" to change the
+members of a group.
+
+Nexus never returns the remote password of a proxy: changing the remote
+authentication needs the password again (--remote-password-stdin), and
+other changes leave the stored authentication as it is.`,
+    Example: `  nexuscli repo update maven-releases --write-policy ALLOW
+  nexuscli repo update npm-hosted --cleanup-policy weekly,old-snapshots
+  nexuscli repo update maven-central --remote-url https://repo.maven.apache.org/maven2/ --metadata-max-age 60`,
+    Args: cobra.ExactArgs(1),
+    Run: func(cmd *cobra.Command, args []string) {
+        repoName := args[0]
+        given := false
+        cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+            given = given || f.Changed
+        })
+        if !given {
+            fmt.Fprintln(os.Stderr, "Error: no settings given; see nexuscli repo update --help or use repo edit.")
+            os.Exit(ExitUsage)
+        }
+        if err := readStdinSecrets(cmd); err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+
+        repo, err := nexusClient.GetRepository(cmd.Context(), repoName)
+        if err != nil {
+            fail(err, "Error reading repository '%s'", repoName)
+        }
+        updated, err := cloneRepository(repo)
+        if err != nil {
+            fail(err, "Error reading repository '%s'", repoName)
+        }
+        if err := applyRepositoryFlags(cmd, updated); err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(ExitUsage)
+        }
+
+        changes := settingsDiff(repo, updated)
+        if len(changes) == 0 {
+            fmt.Printf("Repository '%s' is unchanged.\n", repoName)
+            return
+        }
+        keepStoredPassword(repo, updated)
+        if err := nexusClient.UpdateRepository(cmd.Context(), *updated); err != nil {
+            fail(err, "Error updating repository '%s'", repoName)
+        }
+        fmt.Printf("Repository '%s' updated successfully.\n", repoName)
+        printDiff(changes)
+    },
+}
+
+// applyRepositoryFlags sets the settings given on the command line on
+// repo, leaving the others as they are.
+func applyRepositoryFlags(cmd *cobra.Command, repo *client.Repository) error {
+    changed := cmd.Flags().Changed
+    if changed("blob-store") {
+        return fmt.Errorf("the blob store of a repository cannot be changed")
+    }
+    if err := checkFormatFlags(cmd, repo.Format); err != nil {
+        return err
+    }
+    if err := checkTypeFlags(cmd, repo.Type); err != nil {
+        return err
+    }
+
+    if repo.Storage == nil {
+        repo.Storage = &client.Storage{}
+    }
+    if changed("online") {
+        repo.Online = &repoOnline
+    }
+    if changed("strict-content-type") {
+        repo.Storage.StrictContentTypeValidation = repoStrictContent
+    }
+    if changed("write-policy") {
+        writePolicy := strings.ToUpper(repoWritePolicy)
+        if err := checkEnum("--write-policy", writePolicy, "ALLOW", "ALLOW_ONCE", "DENY"); err != nil {
+            return err
+        }
+        repo.Storage.WritePolicy = writePolicy
+    }
+    // sections are changed in place, keeping the settings the model does
+    // not know
+    if changed("cleanup-policy") {
+        if repo.Cleanup == nil {
+            repo.Cleanup = &client.Cleanup{}
+        }
+        repo.Cleanup.PolicyNames = repoCleanupPolicies
+    }
+    if changed("proprietary-components") {
+        if repo.Component == nil {
+            repo.Component = &client.ComponentConfig{}
+        }
+        repo.Component.ProprietaryComponents = repoProprietary
+    }
+    if repo.Type == "proxy" {
+        if err := applyProxyFlags(cmd, repo); err != nil {
+            return err
+        }
+    }
+
+    switch repo.Format {
+    case "docker":
+        return applyDockerFlags(cmd, repo)
+    case "maven2":
+        if !anyChanged(cmd, "maven-version-policy", "maven-layout-policy", "maven-content-disposition") {
+            break
+        }
+        if repo.Maven == nil {
+            repo.Maven = &client.MavenConfig{}
+        }
+        for _, p := range []struct {
+            flag    string
+            value   string
+            dst     *string
+            allowed []string
+        }{
+            {"maven-version-policy", mavenVersionPolicy, &repo.Maven.VersionPolicy, []string{"RELEASE", "SNAPSHOT", "MIXED"}},
+            {"maven-layout-policy", mavenLayoutPolicy, &repo.Maven.LayoutPolicy, []string{"STRICT", "PERMISSIVE"}},
+            {"maven-content-disposition", mavenContentDisposition, &repo.Maven.ContentDisposition, []string{"INLINE", "ATTACHMENT"}},
+        } {
+            if !changed(p.flag) {
+                continue
+            }
+            value := strings.ToUpper(p.value)
+            if err := checkEnum("--"+p.flag, value, p.allowed...); err != nil {
+                return err
+            }
+            *p.dst = value
+        }
+    case "npm":
+        if anyChanged(cmd, "remove-non-cataloged", "remove-quarantined") {
+            if repo.Npm == nil {
+                repo.Npm = &client.NpmConfig{}
+            }
+            if changed("remove-non-cataloged") {
+                repo.Npm.RemoveNonCataloged = repoRemoveNonCataloged
+            }
+            if changed("remove-quarantined") {
+                repo.Npm.RemoveQuarantined = repoRemoveQuarantined
+            }
+        }
+    case "pypi":
+        if changed("remove-quarantined") {
+            if repo.Pypi == nil {
+                repo.Pypi = &client.PypiConfig{}
+            }
+            repo.Pypi.RemoveQuarantined = repoRemoveQuarantined
+        }
+    case "nuget":
+        if anyChanged(cmd, "nuget-version", "nuget-query-cache-max-age") {
+            if repo.NugetProxy == nil {
+                repo.NugetProxy = &client.NugetProxyConfig{NugetVersion: "V3"}
+            }
+            if changed("nuget-version") {
+                version := strings.ToUpper(nugetVersion)
+                if err := checkEnum("--nuget-version", version, "V2", "V3"); err != nil {
+                    return err
+                }
+                repo.NugetProxy.NugetVersion = version
+            }
+            if changed("nuget-query-cache-max-age") {
+                repo.NugetProxy.QueryCacheItemMaxAge = nugetQueryCacheMaxAge
+            }
+        }
+    case "apt":
+        if repo.Apt == nil && anyChanged(cmd, "apt-distribution", "apt-flat") {
+            repo.Apt = &client.AptConfig{}
+        }
+        if changed("apt-distribution") {
+            if aptDistribution == "" {
+                return fmt.Errorf("--apt-distribution must not be empty")
+            }
+            repo.Apt.Distribution = aptDistribution
+        }
+        if changed("apt-flat") {
+            repo.Apt.Flat = aptFlat
+        }
+        if anyChanged(cmd, "apt-keypair-file", "apt-passphrase", "apt-passphrase-stdin") {
+            if repo.AptSigning == nil {
+                repo.AptSigning = &client.AptSigning{}
+            }
+            if changed("apt-keypair-file") {
+                keypair, err := readAptKeypair()
+                if err != nil {
+                    return err
+                }
+                repo.AptSigning.Keypair = keypair
+            }
+            if secretGiven(cmd, "apt-passphrase") {
+                repo.AptSigning.Passphrase = aptPassphrase
+            }
+        }
+    case "yum":
+        if anyChanged(cmd, "yum-repodata-depth", "yum-deploy-policy") {
+            if repo.Yum == nil {
+                repo.Yum = &client.YumConfig{}
+            }
+            if changed("yum-repodata-depth") {
+                if yumRepodataDepth < 0 || yumRepodataDepth > 5 {
+                    return fmt.Errorf("--yum-repodata-depth must be between 0 and 5, got %d", yumRepodataDepth)
+                }
+                repo.Yum.RepodataDepth = yumRepodataDepth
+            }
+            if changed("yum-deploy-policy") {
+                deployPolicy := strings.ToUpper(yumDeployPolicy)
+                if err := checkEnum("--yum-deploy-policy", deployPolicy, "STRICT", "PERMISSIVE"); err != nil {
+                    return err
+                }
+                repo.Yum.DeployPolicy = deployPolicy
+            }
+        }
+    }
+    return nil
+}
+
+func applyProxyFlags(cmd *cobra.Command, repo *client.Repository) error {
+    changed := cmd.Flags().Changed
+    // Nexus returns every section of a proxy; should one be missing, it
+    // is only added when a flag sets something in it
+    if repo.Proxy == nil {
+        repo.Proxy = &client.ProxyConfig{}
+    }
+    if repo.NegativeCache == nil && anyChanged(cmd, "negative-cache", "negative-cache-ttl") {
+        repo.NegativeCache = &client.NegativeCache{}
+    }
+    connFlags := []string{"http-timeout", "http-retries", "user-agent-suffix",
+        "enable-cookies", "enable-circular-redirects", "use-trust-store"}
+    authFlags := []string{"remote-username", "remote-password", "remote-password-stdin", "remote-auth-type", "ntlm-host", "ntlm-domain"}
+    if repo.HTTPClient == nil && anyChanged(cmd, append(append(connFlags, authFlags...), "auto-block", "blocked")...) {
+        repo.HTTPClient = &client.HTTPClient{}
+    }
+    if repo.HTTPClient != nil && repo.HTTPClient.Connection == nil && anyChanged(cmd, connFlags...) {
+        repo.HTTPClient.Connection = &client.HTTPConnection{}
+    }
+
+    if changed("remote-url") {
+        if err := checkRemoteURL("--remote-url", proxyRemoteURL); err != nil {
+            return err
+        }
+        repo.Proxy.RemoteURL = proxyRemoteURL
+    }
+    if changed("routing-rule") {
+        repo.RoutingRuleName = repoRoutingRule
+    }
+    if changed("content-max-age") {
+        repo.Proxy.ContentMaxAge = proxyContentMaxAge
+    }
+    if changed("metadata-max-age") {
+        repo.Proxy.MetadataMaxAge = proxyMetadataMaxAge
+    }
+    if changed("negative-cache") {
+        repo.NegativeCache.Enabled = proxyNegativeCache
+    }
+    if changed("negative-cache-ttl") {
+        repo.NegativeCache.TimeToLive = proxyNegativeCacheTTL
+    }
+    if changed("auto-block") {
+        repo.HTTPClient.AutoBlock = proxyAutoBlock
+    }
+    if changed("blocked") {
+        repo.HTTPClient.Blocked = proxyBlocked
+    }
+    if conn := repo.HTTPClient.Connection; anyChanged(cmd, connFlags...) {
+        if changed("http-timeout") {
+            conn.Timeout = &proxyHTTPTimeout
+        }
+        if changed("http-retries") {
+            conn.Retries = &proxyHTTPRetries
+        }
+        if changed("user-agent-suffix") {
+            conn.UserAgentSuffix = proxyUserAgentSuffix
+        }
+        if changed("enable-cookies") {
+            conn.EnableCookies = proxyEnableCookies
+        }
+        if changed("enable-circular-redirects") {
+            conn.EnableCircularRedirects = proxyCircularRedirects
+        }
+        if changed("use-trust-store") {
+            conn.UseTrustStore = proxyUseTrustStore
+        }
+    }
+
+    if anyChanged(cmd, authFlags...) {
+        // start from the current authentication so a new password alone
+        // keeps the username
+        auth := client.HTTPAuthentication{Type: "username"}
+        if current := repo.HTTPClient.Authentication; current != nil {
+            auth = *current
+        }
+        for _, f := range []struct {
+            flag  string
+            value string
+            dst   *string
+        }{
+            {"remote-username", proxyRemoteUsername, &auth.Username},
+            {"remote-auth-type", proxyAuthType, &auth.Type},
+            {"ntlm-host", proxyNTLMHost, &auth.NTLMHost},
+            {"ntlm-domain", proxyNTLMDomain, &auth.NTLMDomain},
+        } {
+            if changed(f.flag) {
+                *f.dst = f.value
+            }
+        }
+        passwordGiven := secretGiven(cmd, "remote-password")
+        if passwordGiven {
+            auth.Password = proxyRemotePassword
+        }
+        if auth.Type == "" {
+            auth.Type = "username"
+        }
+        // Nexus does not return the password, so the authentication can
+        // only be sent back with a new one
+        if auth.Username != "" && !passwordGiven {
+            return fmt.Errorf("changing the remote authentication needs --remote-password-stdin or --remote-password: Nexus does not return the stored one")
+        }
+        checked, err := checkAuthentication(auth)
+        if err != nil {
+            return err
+        }
+        repo.HTTPClient.Authentication = checked
+    }
+    return nil
+}
+
+// keepStoredPassword leaves the remote authentication out of after when it
+// is the one read in before. Nexus does not return the password, so
+// sending the block back would clear the stored one.
+func keepStoredPassword(before, after *client.Repository) {
+    if after.HTTPClient == nil || after.HTTPClient.Authentication == nil {
+        return
+    }
+    if before.HTTPClient != nil && reflect.DeepEqual(before.HTTPClient.Authentication, after.HTTPClient.Authentication) {
+        after.HTTPClient.Authentication = nil
+    }
+}
+
+func anyChanged(cmd *cobra.Command, names ...string) bool {
+    for _, name := range names {
+        if cmd.Flags().Changed(name) {
+            return true
+        }
+    }
+    return false
+}
+
+func applyDockerFlags(cmd *cobra.Command, repo *client.Repository) error {
+    changed := cmd.Flags().Changed
+    if repo.Docker == nil {
+        repo.Docker = &client.DockerConfig{}
+    }
+    if changed("docker-v1-enabled") {
+        repo.Docker.V1Enabled = dockerV1Enabled
+    }
+    if changed("docker-force-basic-auth") {
+        repo.Docker.ForceBasicAuth = dockerForceBasicAuth
+    }
+    if changed("docker-subdomain") {
+        repo.Docker.Subdomain = dockerSubdomain
+    }
+    for _, p := range []struct {
+        flag  string
+        value *int
+        dst   **int
+    }{
+        {"docker-http-port", &dockerHTTPPort, &repo.Docker.HTTPPort},
+        {"docker-https-port", &dockerHTTPSPort, &repo.Docker.HTTPSPort},
+    } {
+        if !changed(p.flag) {
+            continue
+        }
+        // 0 closes the connector
+        if *p.value == 0 {
+            *p.dst = nil
+            continue
+        }
+        if *p.value < 1 || *p.value > 65535 {
+            return fmt.Errorf("--%s must be a port between 1 and 65535, or 0 for none", p.flag)
+        }
+        *p.dst = p.value
+    }
+
+    if repo.Type != "proxy" {
+        return nil
+    }
+    if repo.DockerProxy == nil {
+        repo.DockerProxy = &client.DockerProxyConfig{IndexType: "REGISTRY"}
+    }
+    if changed("docker-index-type") {
+        indexType := strings.ToUpper(dockerIndexType)
+        if err := checkEnum("--docker-index-type", indexType, "REGISTRY", "HUB", "CUSTOM"); err != nil {
+            return err
+        }
+        repo.DockerProxy.IndexType = indexType
+    }
+    if changed("docker-index-url") {
+        repo.DockerProxy.IndexURL = dockerIndexURL
+    }
+    if repo.DockerProxy.IndexType == "CUSTOM" && repo.DockerProxy.IndexURL == "" {
+        return fmt.Errorf("--docker-index-type CUSTOM needs --docker-index-url")
+    }
+    if changed("docker-cache-foreign-layers") {
+        repo.DockerProxy.CacheForeignLayers = dockerCacheForeignLayers
+    }
+    if changed("docker-foreign-layer-url") {
+        repo.DockerProxy.ForeignLayerURLWhitelist = dockerForeignLayerURLs
+    }
+    return nil
+}
+
+// checkTypeFlags rejects flags given for another repository type.
+func checkTypeFlags(cmd *cobra.Command, repoType string) error {
+    var err error
+    cmd.Flags().Visit(func(f *pflag.Flag) {
+        types, ok := typeFlags[f.Name]
+        if ok && err == nil && !contains(types, repoType) {
+            err = fmt.Errorf("--%s only applies to %s repositories", f.Name, strings.Join(types, " and "))
+        }
+    })
+    return err
+}
+
+var repoEditCmd = &cobra.Command{
+    Use:   "edit <repo_name>",
+    Short: "Edit the settings of a repository in $EDITOR",
+    Long: `Open the settings of a repository as YAML in $VISUAL or $EDITOR (vi if
+neither is set). When the editor exits the settings are checked and the
+changes are shown; they are only written to Nexus once you confirm.
+
+The name, format, type and blob store cannot be changed.`,
+    Args: cobra.ExactArgs(1),
+    Run: func(cmd *cobra.Command, args []string) {
+        repoName := args[0]
+
+        repo, err := nexusClient.GetRepository(cmd.Context(), repoName)
+        if err != nil {
+            fail(err, "Error reading repository '%s'", repoName)
+        }
+        original, err := repositoryYAML(repo)
+        if err != nil {
+            fail(err, "Error reading repository '%s'", repoName)
+        }
+
+        tmp, err := os.CreateTemp("", "nexuscli-repo-"+repoName+"-*.yaml")
+        if err != nil {
+            fail(err, "Error creating temporary file")
+        }
+        defer os.Remove(tmp.Name())
+        if _, err := tmp.Write(original); err != nil {
+            fail(err, "Error creating temporary file")
+        }
+        tmp.Close()
+
+        for {
+            if err := runEditor(tmp.Name()); err != nil {
+                fail(err, "Error running editor")
+            }
+            edited, err := os.ReadFile(tmp.Name())
+            if err != nil {
+                fail(err, "Error reading edited settings")
+            }
+            if bytes.Equal(edited, original) {
+                fmt.Println("Edit cancelled, no changes made.")
+                return
+            }
+
+            updated, err := parseRepositoryYAML(cmd.Context(), repo, edited)
+            if err == nil {
+                changes := settingsDiff(repo, updated)
+                if len(changes) == 0 {
+                    fmt.Println("No settings changed.")
+                    return
+                }
+                printDiff(changes)
+                if !confirm(fmt.Sprintf("Apply these changes to '%s'?", repoName), false) {
+                    fmt.Fprintln(os.Stderr, "Changes discarded.")
+                    return
+                }
+                keepStoredPassword(repo, updated)
+                err = nexusClient.UpdateRepository(cmd.Context(), *updated)
+                if err == nil {
+                    fmt.Printf("Repository '%s' updated successfully.\n", repoName)
+                    return
+                }
+                if !client.IsValidation(err) {
+                    fail(err, "Error updating repository '%s'", repoName)
+                }
+            }
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            if !confirm("Edit again?", false) {
+                fmt.Fprintln(os.Stderr, "Changes discarded.")
+                os.Exit(exitCode(err))
+            }
+        }
+    },
+}
+
+// repositoryYAML renders the editable settings of repo, with a header
+// naming what cannot be changed. The URL is left out as it is derived.
+func repositoryYAML(repo *client.Repository) ([]byte, error) {
+    doc, err := repositoryDocument(repo)
+    if err != nil {
+        return nil, err
+    }
+    delete(doc, "url")
+    data, err := yaml.Marshal(doc)
+    if err != nil {
+        return nil, err
+    }
+    header := fmt.Sprintf("# Settings of the %s %s repository '%s'.\n"+
+        "# name, format, type and storage.blobStoreName cannot be changed.\n"+
+        "# Save and quit to review the changes; leave the file as is to cancel.\n",
+        repo.Format, repo.Type, repo.Name)
+    return append([]byte(header), data...), nil
+}
+
+// parseRepositoryYAML reads edited settings of current and checks what
+// Nexus would reject with a less helpful message.
+func parseRepositoryYAML(ctx context.Context, current *client.Repository, data []byte) (*client.Repository, error) {
+    var doc map[string]interface{}
+    if err := yaml.Unmarshal(data, &doc); err != nil {
+        return nil, usageError{fmt.Errorf("invalid YAML: %v", err)}
+    }
+    raw, err := json.Marshal(doc)
+    if err != nil {
+        return nil, usageError{fmt.Errorf("invalid settings: %v", err)}
+    }
+    repo := &client.Repository{}
+    if err := json.Unmarshal(raw, repo); err != nil {
+        var typeErr *json.UnmarshalTypeError
+        if errors.As(err, &typeErr) {
+            return nil, usageError{fmt.Errorf("%s: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)}
+        }
+        return nil, usageError{fmt.Errorf("invalid settings: %v", err)}
+    }
+
+    for _, f := range []struct{ name, was, is string }{
+        {"name", current.Name, repo.Name},
+        {"format", current.Format, repo.Format},
+        {"type", current.Type, repo.Type},
+    } {
+        if f.is != f.was {
+            return nil, usageError{fmt.Errorf("%s cannot be changed (was %q, now %q)", f.name, f.was, f.is)}
+        }
+    }
+    repo.URL = current.URL
+    if repo.Storage == nil || repo.Storage.BlobStoreName == "" {
+        return nil, usageError{fmt.Errorf("storage.blobStoreName is required")}
+    }
+    if current.Storage != nil && repo.Storage.BlobStoreName != current.Storage.BlobStoreName {
+        return nil, usageError{fmt.Errorf("storage.blobStoreName cannot be changed")}
+    }
+    if repo.Type == "hosted" {
+        if err := checkEnum("storage.writePolicy", repo.Storage.WritePolicy, "ALLOW", "ALLOW_ONCE", "DENY"); err != nil {
+            return nil, usageError{err}
+        }
+    }
+    if repo.Type == "proxy" {
+        if repo.Proxy == nil || repo.Proxy.RemoteURL == "" {
+            return nil, usageError{fmt.Errorf("proxy.remoteUrl is required")}
+        }
+        if err := checkRemoteURL("proxy.remoteUrl", repo.Proxy.RemoteURL); err != nil {
+            return nil, usageError{err}
+        }
+        if h := repo.HTTPClient; h != nil && h.Authentication != nil && h.Authentication.Password == "" &&
+            (current.HTTPClient == nil || !reflect.DeepEqual(current.HTTPClient.Authentication, h.Authentication)) {
+            return nil, usageError{fmt.Errorf("httpClient.authentication.password is required when the authentication changes: Nexus does not return the stored one")}
+        }
+    }
+    if repo.Type == "group" {
+        if repo.Group == nil {
+            return nil, usageError{fmt.Errorf("group.memberNames is required")}
+        }
+        members, err := uniqueMembers(repo.Group.MemberNames)
+        if err != nil {
+            return nil, usageError{err}
+        }
+        if err := checkMembers(ctx, repo.Format, repo.Name, members); err != nil {
+            return nil, err
+        }
+    }
+    if repo.Maven != nil {
+        if err := checkEnum("maven.versionPolicy", repo.Maven.VersionPolicy, "RELEASE", "SNAPSHOT", "MIXED"); err != nil {
+            return nil, usageError{err}
+        }
+        if err := checkEnum("maven.layoutPolicy", repo.Maven.LayoutPolicy, "STRICT", "PERMISSIVE"); err != nil {
+            return nil, usageError{err}
+        }
+    }
+    if repo.Docker != nil {
+        for name, port := range map[string]*int{"docker.httpPort": repo.Docker.HTTPPort, "docker.httpsPort": repo.Docker.HTTPSPort} {
+            if port != nil && (*port < 1 || *port > 65535) {
+                return nil, usageError{fmt.Errorf("%s must be a port between 1 and 65535", name)}
+            }
+        }
+    }
+    return repo, nil
+}
+
+// cloneRepository returns a deep copy of repo.
+func cloneRepository(repo *client.Repository) (*client.Repository, error) {
+    data, err := json.Marshal(repo)
+    if err != nil {
+        return nil, err
+    }
+    clone := &client.Repository{}
+    if err := json.Unmarshal(data, clone); err != nil {
+        return nil, err
+    }
+    return clone, nil
+}
+
+// settingsDiff lists the settings that differ between before and after as
+// "- name: old" and "+ name: new" lines.
+func settingsDiff(before, after *client.Repository) []string {
+    settings := func(repo *client.Repository) ([][2]string, map[string]string) {
+        doc, _ := repositoryDocument(repo)
+        rows := flattenSettings("", doc)
+        values := map[string]string{}
+        for _, r := range rows {
+            values[r[0]] = r[1]
+        }
+        return rows, values
+    }
+    oldRows, oldValues := settings(before)
+    newRows, newValues := settings(after)
+
+    line := func(sign, name, value string) string {
+        return strings.TrimRight(sign+" "+name+": "+maskSetting(name, value), " ")
+    }
+    lines := []string{}
+    for _, r := range oldRows {
+        if v, ok := newValues[r[0]]; !ok || v != r[1] {
+            lines = append(lines, line("-", r[0], r[1]))
+            if ok {
+                lines = append(lines, line("+", r[0], v))
+            }
+        }
+    }
+    for _, r := range newRows {
+        if _, ok := oldValues[r[0]]; !ok {
+            lines = append(lines, line("+", r[0], r[1]))
+        }
+    }
+    return lines
+}
+
+func printDiff(lines []string) {
+    for _, l := range lines {
+        if outputFormat == "color" {
+            color := "32"
+            if strings.HasPrefix(l, "-") {
+                color = "31"
+            }
+            fmt.Printf("\033[%sm%s\033[0m\n", color, l)
+            continue
+        }
+        fmt.Println(l)
+    }
+}
+
+func init() {
+    repoCmd.AddCommand(repoUpdateCmd)
+    repoCmd.AddCommand(repoEditCmd)
+
+    addStorageFlags(repoUpdateCmd)
+    addFormatFlags(repoUpdateCmd)
+    repoUpdateCmd.Flags().StringSliceVar(&repoCleanupPolicies, "cleanup-policy", nil, "Cleanup policies to apply")
+    addHostedFlags(repoUpdateCmd)
+    addProxyFlags(repoUpdateCmd)
+    // only the flags given change anything, so there are no defaults
+    repoUpdateCmd.Flags().VisitAll(func(f *pflag.Flag) {
+        switch f.Value.Type() {
+        case "bool":
+            f.DefValue = "false"
+        case "int":
+            f.DefValue = "0"
+        case "stringSlice":
+            f.DefValue = "[]"
+        default:
+            f.DefValue = ""
+        }
+    })
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+    "context"
+    "encoding/json"
+    "reflect"
+    "strings"
+    "testing"
+    "nexuscli/internal/client"
+)
+
+// testRepository decodes a repository as Nexus returns it.
+func testRepository(t *testing.T, data string) *client.Repository {
+    t.Helper()
+    repo := &client.Repository{}
+    if err := json.Unmarshal([]byte(data), repo); err != nil {
+        t.Fatal(err)
+    }
+    return repo
+}
+
+const testProxy = `{
+    "name": "maven-central", "format": "maven2", "type": "proxy", "url": "http://nexus/repository/maven-central",
+    "online": true,
+    "storage": {"blobStoreName": "default", "strictContentTypeValidation": true, "dataStoreName": "nexus"},
+    "proxy": {"remoteUrl": "https://repo1.maven.org/maven2/", "contentMaxAge": -1, "metadataMaxAge": 1440},
+    "negativeCache": {"enabled": true, "timeToLive": 1440},
+    "httpClient": {"blocked": false, "autoBlock": true, "authentication": {"type": "username", "username": "ci"}},
+    "maven": {"versionPolicy": "RELEASE", "layoutPolicy": "STRICT"}
+}`
+
+func TestKeepStoredPassword(t *testing.T) {
+    tests := []struct {
+        name     string
+        after    string
+        wantAuth bool
+    }{
+        {"unchanged authentication is left out", `{"type": "username", "username": "ci"}`, false},
+        {"new username is sent", `{"type": "username", "username": "deploy", "password": "p"}`, true},
+        {"new password is sent", `{"type": "username", "username": "ci", "password": "p"}`, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            before := testRepository(t, testProxy)
+            after, _ := cloneRepository(before)
+            after.HTTPClient.Authentication = &client.HTTPAuthentication{}
+            json.Unmarshal([]byte(tt.after), after.HTTPClient.Authentication)
+
+            keepStoredPassword(before, after)
+            if got := after.HTTPClient.Authentication != nil; got != tt.wantAuth {
+                t.Errorf("authentication sent = %v, want %v", got, tt.wantAuth)
+            }
+            if !after.HTTPClient.AutoBlock {
+                t.Error("the rest of the HTTP client settings was dropped")
+            }
+        })
+    }
+}
+
+func TestParseRepositoryYAML(t *testing.T) {
+    tests := []struct {
+        name    string
+        edit    func(string) string
+        wantErr string
+    }{
+        {name: "unchanged", edit: func(s string) string { return s }},
+        {
+            name: "new remote URL",
+            edit: func(s string) string {
+                return strings.Replace(s, "https://repo1.maven.org/maven2/", "https://repo.maven.apache.org/maven2/", 1)
+            },
+        },
+        {
+            name:    "renamed",
+            edit:    func(s string) string { return strings.Replace(s, "name: maven-central", "name: other", 1) },
+            wantErr: "name cannot be changed",
+        },
+        {
+            name:    "other blob store",
+            edit:    func(s string) string { return strings.Replace(s, "blobStoreName: default", "blobStoreName: fast", 1) },
+            wantErr: "storage.blobStoreName cannot be changed",
+        },
+        {
+            name:    "changed authentication without password",
+            edit:    func(s string) string { return strings.Replace(s, "username: ci", "username: deploy", 1) },
+            wantErr: "httpClient.authentication.password is required",
+        },
+        {
+            name: "changed authentication with password",
+            edit: func(s string) string {
+                return strings.Replace(s, "username: ci", "username: deploy\n        password: s3cr3t", 1)
+            },
+        },
+        {
+            name:    "bad version policy",
+            edit:    func(s string) string { return strings.Replace(s, "versionPolicy: RELEASE", "versionPolicy: LATEST", 1) },
+            wantErr: "maven.versionPolicy",
+        },
+        {
+            name:    "wrong type",
+            edit:    func(s string) string { return strings.Replace(s, "metadataMaxAge: 1440", "metadataMaxAge: daily", 1) },
+            wantErr: "metadataMaxAge: expected int",
+        },
+        {
+            name:    "not YAML",
+            edit:    func(s string) string { return s + "\n  : : :" },
+            wantErr: "invalid YAML",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            current := testRepository(t, testProxy)
+            data, err := repositoryYAML(current)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            repo, err := parseRepositoryYAML(context.Background(), current, []byte(tt.edit(string(data))))
+            if tt.wantErr != "" {
+                if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                    t.Fatalf("got %v, want an error about %q", err, tt.wantErr)
+                }
+                if exitCode(err) != ExitUsage {
+                    t.Errorf("exit code %d, want %d", exitCode(err), ExitUsage)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatal(err)
+            }
+            if repo.URL != current.URL {
+                t.Errorf("URL = %q, want %q", repo.URL, current.URL)
+            }
+            if _, ok := repo.Storage.Extra["dataStoreName"]; !ok {
+                t.Error("unknown storage settings were lost")
+            }
+        })
+    }
+}
+
+func TestSettingsDiff(t *testing.T) {
+    before := testRepository(t, testProxy)
+    after, _ := cloneRepository(before)
+    after.Proxy.MetadataMaxAge = 60
+    after.HTTPClient.Authentication.Password = "s3cr3t"
+    after.Cleanup = &client.Cleanup{PolicyNames: []string{"weekly"}}
+
+    want := []string{
+        "- proxy.metadataMaxAge: 1440",
+        "+ proxy.metadataMaxAge: 60",
+        "+ cleanup.policyNames: weekly",
+        "+ httpClient.authentication.password: ****",
+    }
+    if got := settingsDiff(before, after); !reflect.DeepEqual(got, want) {
+        t.Errorf("got  %q\nwant %q", got, want)
+    }
+    if got := settingsDiff(before, before); len(got) != 0 {
+        t.Errorf("no changes gave %q", got)
+    }
+}
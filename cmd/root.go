@@ -1,49 +1,215 @@
 package cmd
 
 import (
+    "context"
     "fmt"
     "os"
+    "os/signal"
+    "strings"
+    "syscall"
+    "time"
     "nexuscli/config"
     "nexuscli/internal/client"
     "github.com/spf13/cobra"
+    "github.com/spf13/viper"
 )
 
 var (
     outputFormat string
     nexusClient  *client.NexusClient
     verbosity      int
+    listLimit    int
+    listPageSize int
+    dumpCurl     bool
+    dryRun       bool
 )
 
 var rootCmd = &cobra.Command{
     Use:   "nexuscli",
     Short: "CLI tool for Sonatype Nexus",
+    Long: `CLI tool for Sonatype Nexus.
+
+Exit codes:
+  0    success
+  1    unclassified error
+  2    invalid arguments, flags or config file
+  3    authentication failed or insufficient privileges (401/403)
+  4    object not found (404)
+  5    object already exists
+  6    request rejected by Nexus (400)
+  7    Nexus server error (5xx)
+  8    Nexus unreachable (connection, DNS or timeout error)
+  130  interrupted by SIGINT/SIGTERM`,
+    // Execute prints the error; usage is only shown for bad flags and
+    // arguments, which cobra reports before PersistentPreRunE runs
+    SilenceErrors: true,
     PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+        cmd.SilenceUsage = true
         if err := config.InitViper(); err != nil {
             return err
         }
+        for _, w := range config.Warnings {
+            fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+        }
+        if config.Global.InsecureSkipVerify {
+            fmt.Fprintln(os.Stderr, "WARNING: TLS certificate verification is disabled (insecureSkipVerify).")
+            fmt.Fprintln(os.Stderr, "WARNING: Credentials and data can be intercepted. Do not use this in production.")
+        }
 
-        nexusClient = client.NewNexusClient(
-            config.Global.URL,
-            config.Global.Username,
-            config.Global.Password,
-            config.Global.Token,
-            config.Global.Timeout,
-            verbosity,
-        )
+        // config, login and logout must work on a context that does not
+        // exist yet, without working credentials and with TLS or proxy
+        // settings that break the client, so they can repair them; doctor
+        // and login build their own client
+        if managesConfig(cmd) {
+            return nil
+        }
+        if err := config.CheckContext(); err != nil {
+            return err
+        }
+        if err := config.ResolveCredentials(); err != nil {
+            return err
+        }
+        c, err := newClient(config.Global)
+        if err != nil {
+            return err
+        }
+        nexusClient = c
         return nil
     },
 }
 
+func managesConfig(cmd *cobra.Command) bool {
+    for c := cmd; c != nil; c = c.Parent() {
+        if c == configCmd || c == loginCmd || c == logoutCmd {
+            return true
+        }
+    }
+    return false
+}
+
+// newClient builds a Nexus client from cfg and the global flags. Its
+// errors come from the settings and exit with ExitUsage.
+func newClient(cfg config.Config) (*client.NexusClient, error) {
+    c, err := client.New(cfg.URL,
+        client.WithBasicAuth(cfg.Username, cfg.Password),
+        client.WithToken(cfg.Token),
+        client.WithTimeout(time.Duration(cfg.Timeout)*time.Second),
+        client.WithVerbosity(verbosity),
+        client.WithLogFormat(cfg.LogFormat),
+        client.WithRetryPolicy(client.RetryPolicy{
+            MaxRetries: cfg.Retries,
+            BaseWait:   client.DefaultRetryPolicy.BaseWait,
+            MaxWait:    time.Duration(cfg.RetryMaxWait) * time.Second,
+        }),
+        client.WithTLS(client.TLSOptions{
+            InsecureSkipVerify: cfg.InsecureSkipVerify,
+            CAFile:             cfg.CACert,
+            CertFile:           cfg.ClientCert,
+            KeyFile:            cfg.ClientKey,
+            MinVersion:         cfg.TLSMinVersion,
+        }),
+        client.WithProxy(client.ProxyOptions{
+            URL:      cfg.Proxy,
+            Username: cfg.ProxyUsername,
+            Password: cfg.ProxyPassword,
+            NoProxy:  cfg.NoProxy,
+        }),
+    )
+    if err != nil {
+        return nil, usageError{err}
+    }
+    c.SetCurlDump(dumpCurl, dryRun)
+    return c, nil
+}
+
+// Execute runs the root command. SIGINT and SIGTERM cancel the command's
+// context, which aborts requests in flight.
 func Execute() {
-    if err := rootCmd.Execute(); err != nil {
-        fmt.Println(err)
-        os.Exit(1)
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    usageArgs(rootCmd)
+    if err := rootCmd.ExecuteContext(ctx); err != nil {
+        // cobra reports an unknown subcommand of the root as a plain error
+        if strings.HasPrefix(err.Error(), "unknown command ") {
+            err = usageError{err}
+        }
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+        os.Exit(exitCode(err))
+    }
+}
+
+// usageArgs wraps the argument validators of cmd and its subcommands, so
+// a wrong number of arguments exits with ExitUsage like a bad flag does.
+func usageArgs(cmd *cobra.Command) {
+    if validate := cmd.Args; validate != nil {
+        cmd.Args = func(c *cobra.Command, args []string) error {
+            if err := validate(c, args); err != nil {
+                return usageError{err}
+            }
+            return nil
+        }
+    }
+    for _, c := range cmd.Commands() {
+        usageArgs(c)
     }
 }
 
 func init() {
     rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table",
-        "Output format: table, json, yaml, color")
+        "Output format: table, wide (repo list), csv (repo stats), json, yaml, color")
+    rootCmd.PersistentFlags().String("config", "",
+        "Config file to use instead of the user and project-local ones")
+    _ = viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
+    rootCmd.PersistentFlags().String("context", "",
+        "Config context to use (overrides NEXUS_CONTEXT and currentContext)")
+    _ = viper.BindPFlag("context", rootCmd.PersistentFlags().Lookup("context"))
     rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v",
         "Increase verbosity level (use -v for basic, -vv for debug)")
+    rootCmd.PersistentFlags().String("log-format", "text",
+        "Format of the diagnostics written to stderr: text or json")
+    _ = viper.BindPFlag("logFormat", rootCmd.PersistentFlags().Lookup("log-format"))
+    rootCmd.PersistentFlags().BoolVar(&dumpCurl, "dump-curl", false,
+        "Print an equivalent curl command to stderr for every API call")
+    rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false,
+        "Print the curl commands without sending any request (implies --dump-curl)")
+    rootCmd.PersistentFlags().Int("retries", 3,
+        "Number of retries for transient failures (429, 502, 503, 504, connection errors)")
+    rootCmd.PersistentFlags().Int("retry-max-wait", 30,
+        "Maximum wait between retries in seconds")
+    _ = viper.BindPFlag("retries", rootCmd.PersistentFlags().Lookup("retries"))
+    _ = viper.BindPFlag("retryMaxWait", rootCmd.PersistentFlags().Lookup("retry-max-wait"))
+
+    rootCmd.PersistentFlags().Bool("insecure-skip-verify", false,
+        "Skip TLS certificate verification (insecure)")
+    rootCmd.PersistentFlags().String("ca-cert", "", "Path to a PEM CA bundle used to verify Nexus")
+    rootCmd.PersistentFlags().String("client-cert", "", "Path to a PEM client certificate for mutual TLS")
+    rootCmd.PersistentFlags().String("client-key", "", "Path to the PEM private key of the client certificate")
+    rootCmd.PersistentFlags().String("tls-min-version", "", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3 (default 1.2)")
+    _ = viper.BindPFlag("insecureSkipVerify", rootCmd.PersistentFlags().Lookup("insecure-skip-verify"))
+    _ = viper.BindPFlag("caCert", rootCmd.PersistentFlags().Lookup("ca-cert"))
+    _ = viper.BindPFlag("clientCert", rootCmd.PersistentFlags().Lookup("client-cert"))
+    _ = viper.BindPFlag("clientKey", rootCmd.PersistentFlags().Lookup("client-key"))
+    _ = viper.BindPFlag("tlsMinVersion", rootCmd.PersistentFlags().Lookup("tls-min-version"))
+
+    rootCmd.PersistentFlags().String("proxy", "",
+        "Proxy URL for Nexus requests, or \"direct\" to bypass HTTPS_PROXY")
+    rootCmd.PersistentFlags().StringSlice("no-proxy", nil,
+        "Comma-separated hosts, domains or CIDRs reached without the proxy")
+    _ = viper.BindPFlag("proxy", rootCmd.PersistentFlags().Lookup("proxy"))
+    _ = viper.BindPFlag("noProxy", rootCmd.PersistentFlags().Lookup("no-proxy"))
+
+    rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+        return usageError{err}
+    })
+}
+
+// addListFlags registers the paging flags shared by every list command.
+func addListFlags(cmd *cobra.Command) {
+    cmd.Flags().IntVar(&listLimit, "limit", 0, "Maximum number of items to return (0 = all)")
+    cmd.Flags().IntVar(&listPageSize, "page-size", 0, "Items to request per page (0 = server default)")
+}
+
+func listOptions() client.ListOptions {
+    return client.ListOptions{Limit: listLimit, PageSize: listPageSize}
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "github.com/spf13/cobra"
+)
+
+// isolateConfig points the user config at an empty temporary home and
+// clears the NEXUS_* variables, so a test only sees the settings it sets.
+func isolateConfig(t *testing.T) {
+    t.Helper()
+    home := t.TempDir()
+    t.Setenv("HOME", home)
+    t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+    for _, env := range os.Environ() {
+        if name, _, _ := strings.Cut(env, "="); strings.HasPrefix(name, "NEXUS_") {
+            t.Setenv(name, "")
+            os.Unsetenv(name)
+        }
+    }
+    chdir(t, home)
+}
+
+// chdir changes into dir for the rest of the test.
+func chdir(t *testing.T, dir string) {
+    t.Helper()
+    old, err := os.Getwd()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := os.Chdir(dir); err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { os.Chdir(old) })
+}
+
+func TestRootBrokenTLSSettings(t *testing.T) {
+    isolateConfig(t)
+    t.Setenv("NEXUS_URL", "https://nexus.example.com")
+    t.Setenv("NEXUS_CACERT", filepath.Join(t.TempDir(), "missing.pem"))
+
+    tests := []struct {
+        cmd      *cobra.Command
+        wantCode int
+    }{
+        {configSetCmd, ExitOK},
+        {configUnsetCmd, ExitOK},
+        {configEditCmd, ExitOK},
+        {configDoctorCmd, ExitOK},
+        {loginCmd, ExitOK},
+        {repoListCmd, ExitUsage},
+    }
+    for _, tt := range tests {
+        t.Run(tt.cmd.CommandPath(), func(t *testing.T) {
+            nexusClient = nil
+            err := rootCmd.PersistentPreRunE(tt.cmd, nil)
+            if got := exitCode(err); got != tt.wantCode {
+                t.Errorf("got %v (exit code %d), want exit code %d", err, got, tt.wantCode)
+            }
+            if err == nil && nexusClient != nil {
+                t.Error("a client was built for a command that manages the config")
+            }
+        })
+    }
+}
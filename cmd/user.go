@@ -30,14 +30,13 @@ var userCreateCmd = &cobra.Command{
         username := args[0]
 
         if userPassword == "" || userEmail == "" {
-            fmt.Println("Error: --password and --email are required.")
+            fmt.Fprintln(os.Stderr, "Error: --password and --email are required.")
             _ = cmd.Help()
-            os.Exit(1)
+            os.Exit(ExitUsage)
         }
 
-        if err := nexusClient.CreateUser(username, userPassword, userFirstName, userLastName, userEmail, userRoles); err != nil {
-            fmt.Printf("Error creating user '%s': %v\n", username, err)
-            os.Exit(1)
+        if err := nexusClient.CreateUser(cmd.Context(), username, userPassword, userFirstName, userLastName, userEmail, userRoles); err != nil {
+            fail(err, "Error creating user '%s'", username)
         }
         fmt.Printf("User '%s' created successfully.\n", username)
     },
@@ -50,9 +49,8 @@ var userDeleteCmd = &cobra.Command{
     Run: func(cmd *cobra.Command, args []string) {
         username := args[0]
 
-        if err := nexusClient.DeleteUser(username); err != nil {
-            fmt.Printf("Error deleting user '%s': %v\n", username, err)
-            os.Exit(1)
+        if err := nexusClient.DeleteUser(cmd.Context(), username); err != nil {
+            fail(err, "Error deleting user '%s'", username)
         }
         fmt.Printf("User '%s' deleted successfully.\n", username)
     },
@@ -62,10 +60,9 @@ var userListCmd = &cobra.Command{
     Use:   "list",
     Short: "List all Nexus users",
     Run: func(cmd *cobra.Command, args []string) {
-        users, err := nexusClient.ListUsers()
+        users, err := nexusClient.ListUsers(cmd.Context(), listOptions()).All()
         if err != nil {
-            fmt.Printf("Error listing users: %v\n", err)
-            os.Exit(1)
+            fail(err, "Error listing users")
         }
 
         if len(users) == 0 {
@@ -75,21 +72,13 @@ var userListCmd = &cobra.Command{
 
         items := []map[string]interface{}{}
         for _, user := range users {
-            roles := []string{}
-            if r, ok := user["roles"].([]interface{}); ok {
-                for _, role := range r {
-                    if s, ok := role.(string); ok {
-                        roles = append(roles, s)
-                    }
-                }
-            }
             items = append(items, map[string]interface{}{
-                "USER ID":    user["userId"],
-                "FIRST NAME": user["firstName"],
-                "LAST NAME":  user["lastName"],
-                "EMAIL":      user["emailAddress"],
-                "STATUS":     user["status"],
-                "ROLES":      strings.Join(roles, ", "),
+                "USER ID":    user.UserID,
+                "FIRST NAME": user.FirstName,
+                "LAST NAME":  user.LastName,
+                "EMAIL":      user.EmailAddress,
+                "STATUS":     user.Status,
+                "ROLES":      strings.Join(user.Roles, ", "),
             })
         }
 
@@ -107,6 +96,8 @@ func init() {
     userCmd.AddCommand(userDeleteCmd)
     userCmd.AddCommand(userListCmd)
 
+    addListFlags(userListCmd)
+
     userCreateCmd.Flags().StringVarP(&userPassword, "password", "p", "", "Password for the new user (required)")
     userCreateCmd.Flags().StringVarP(&userFirstName, "first-name", "f", "", "First name of the new user")
     userCreateCmd.Flags().StringVarP(&userLastName, "last-name", "l", "", "Last name of the new user")
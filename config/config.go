@@ -1,9 +1,9 @@
 package config
 
 import (
+    "errors"
     "fmt"
-    "os"
-    "path/filepath"
+    "nexuscli/internal/credentials"
 
     "github.com/spf13/viper"
 )
@@ -14,20 +14,72 @@ type Config struct {
     Password string `mapstructure:"password"`
     Token    string `mapstructure:"token"`
     Timeout  int    `mapstructure:"timeout"`
+    // Retries is the number of times a transient failure is retried.
+    Retries int `mapstructure:"retries"`
+    // RetryMaxWait caps the wait between two retries, in seconds.
+    RetryMaxWait int `mapstructure:"retryMaxWait"`
+
+    InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+    CACert             string `mapstructure:"caCert"`
+    ClientCert         string `mapstructure:"clientCert"`
+    ClientKey          string `mapstructure:"clientKey"`
+    TLSMinVersion      string `mapstructure:"tlsMinVersion"`
+
+    // Proxy is the proxy URL, or "direct" to ignore HTTPS_PROXY.
+    Proxy         string   `mapstructure:"proxy"`
+    ProxyUsername string   `mapstructure:"proxyUsername"`
+    ProxyPassword string   `mapstructure:"proxyPassword"`
+    NoProxy       []string `mapstructure:"noProxy"`
+
+    // LogFormat is text or json; diagnostics always go to stderr.
+    LogFormat string `mapstructure:"logFormat"`
+
+    // CredentialStore is where the password and token are kept: keyring,
+    // file or plain (in this config file). Empty means plain.
+    CredentialStore string `mapstructure:"credentialStore"`
+    // CredentialHelper names an external program (nexuscli-credential-<name>)
+    // that supplies the credentials at runtime.
+    CredentialHelper string `mapstructure:"credentialHelper"`
+
+    // ServerVersion and ServerEdition are recorded by login.
+    ServerVersion string `mapstructure:"serverVersion"`
+    ServerEdition string `mapstructure:"serverEdition"`
 }
 
 var Global Config
 
-// InitViper sets up viper with defaults and config file
-func InitViper() error {
-    home, err := os.UserHomeDir()
-    if err != nil {
-        return fmt.Errorf("could not find home dir: %w", err)
-    }
+const (
+    contextsKey       = "contexts"
+    currentContextKey = "currentContext"
+)
 
-    cfgFile := filepath.Join(home, ".nexuscli.yaml")
+// ActiveContext is the name of the context Global was loaded from: the
+// --context flag, then NEXUS_CONTEXT, then currentContext in the file.
+// It is empty when only top-level settings are used.
+var ActiveContext string
 
-    viper.SetConfigFile(cfgFile)
+// ErrContextNotFound is returned by CheckContext when the selected context
+// does not exist in the config file.
+var ErrContextNotFound = errors.New("context not found")
+
+var contextMissing bool
+
+// InitViper sets up viper with defaults and config files. Settings are
+// resolved in this order, later ones winning:
+//
+//  1. defaults
+//  2. the user config file: --config/NEXUS_CONFIG if given, otherwise
+//     $XDG_CONFIG_HOME/nexuscli/config.yaml or the legacy ~/.nexuscli.yaml
+//  3. the nearest .nexuscli.yml in the working directory or its parents
+//     (skipped when --config/NEXUS_CONFIG is given); only the projectKeys
+//     settings are read from it, and the trustedProjectKeys once the user
+//     trusted it
+//  4. the active context of the merged files
+//  5. NEXUS_* environment variables
+//  6. command line flags
+//
+// Files may use the flat schema or the nested "nexus:" one.
+func InitViper() error {
     viper.SetConfigType("yaml")
 
     // Defaults
@@ -36,32 +88,124 @@ func InitViper() error {
     viper.SetDefault("password", "")
     viper.SetDefault("token", "")
     viper.SetDefault("timeout", 30)
+    viper.SetDefault("retries", 3)
+    viper.SetDefault("retryMaxWait", 30)
+    viper.SetDefault("insecureSkipVerify", false)
+    viper.SetDefault("caCert", "")
+    viper.SetDefault("clientCert", "")
+    viper.SetDefault("clientKey", "")
+    viper.SetDefault("tlsMinVersion", "")
+    viper.SetDefault("proxy", "")
+    viper.SetDefault("proxyUsername", "")
+    viper.SetDefault("proxyPassword", "")
+    viper.SetDefault("noProxy", []string{})
+    viper.SetDefault("logFormat", "text")
+    viper.SetDefault("credentialStore", "")
+    viper.SetDefault("credentialHelper", "")
+    viper.SetDefault("serverVersion", "")
+    viper.SetDefault("serverEdition", "")
 
     // ENV support (NEXUS_URL, NEXUS_USERNAME, ...)
     viper.SetEnvPrefix("NEXUS")
     viper.AutomaticEnv()
 
-    // Load config if exists
-    if err := viper.ReadInConfig(); err != nil {
-        // ignore if file does not exist
-        if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-            return fmt.Errorf("failed reading config: %w", err)
+    files, project, err := configFiles(viper.GetString("config"))
+    if err != nil {
+        return err
+    }
+    LoadedFiles = nil
+    Warnings = nil
+    ProjectFile = project
+    merged := map[string]interface{}{}
+    for _, path := range files {
+        data, err := readConfigFile(path)
+        if err != nil {
+            return err
+        }
+        if data == nil {
+            continue
+        }
+        if path == project {
+            trusted, seen, lapsed := projectTrust(path)
+            if dropped := restrictProjectFile(data, trusted); len(dropped) > 0 && !seen {
+                Warnings = append(Warnings, projectFileWarning(path, dropped, lapsed))
+            }
+        }
+        LoadedFiles = append(LoadedFiles, LoadedFile{Path: path, Data: data})
+        mergeMaps(merged, data)
+    }
+
+    // Settings of the active context override the top-level ones; flags
+    // and NEXUS_* variables still override both.
+    ActiveContext = viper.GetString("context")
+    if ActiveContext == "" {
+        ActiveContext, _ = merged[currentContextKey].(string)
+    }
+    contextMissing = false
+    if ActiveContext != "" {
+        contexts, _ := merged[contextsKey].(map[string]interface{})
+        section, ok := contexts[ActiveContext].(map[string]interface{})
+        if !ok {
+            contextMissing = true
+        } else {
+            mergeMaps(merged, section)
         }
     }
+    if err := viper.MergeConfigMap(merged); err != nil {
+        return fmt.Errorf("failed loading config: %w", err)
+    }
 
     return viper.Unmarshal(&Global)
 }
 
-// SaveConfig writes current viper state to file
-func SaveConfig() error {
-    home, err := os.UserHomeDir()
-    if err != nil {
-        return fmt.Errorf("could not find home dir: %w", err)
+// CheckContext fails when the selected context does not exist.
+func CheckContext() error {
+    if contextMissing {
+        return fmt.Errorf("%w: %q", ErrContextNotFound, ActiveContext)
+    }
+    return nil
+}
+
+// ResolveCredentials fills in the password and token of Global that are
+// not kept in plain text: from the credential helper if one is set,
+// otherwise from the credential store.
+func ResolveCredentials() error {
+    if Global.Password != "" || Global.Token != "" {
+        return nil
+    }
+
+    if Global.CredentialHelper != "" {
+        creds, err := credentials.Helper{Name: Global.CredentialHelper}.Get(Global.URL)
+        if err != nil {
+            return err
+        }
+        if creds.Username == credentials.TokenUsername {
+            Global.Token = creds.Secret
+            return nil
+        }
+        if creds.Username != "" {
+            Global.Username = creds.Username
+        }
+        Global.Password = creds.Secret
+        return nil
     }
-    cfgFile := filepath.Join(home, ".nexuscli.yaml")
 
-    if err := viper.WriteConfigAs(cfgFile); err != nil {
-        return fmt.Errorf("could not write config: %w", err)
+    if Global.CredentialStore == "" || Global.CredentialStore == credentials.BackendPlain {
+        return nil
+    }
+    store, err := credentials.Open(Global.CredentialStore)
+    if err != nil {
+        return err
+    }
+    for kind, target := range map[string]*string{"password": &Global.Password, "token": &Global.Token} {
+        secret, err := store.Get(credentials.Account(ActiveContext, kind))
+        if errors.Is(err, credentials.ErrNotFound) {
+            continue
+        }
+        if err != nil {
+            return fmt.Errorf("could not read %s from %s store: %w", kind, store.Name(), err)
+        }
+        *target = secret
     }
     return nil
 }
@@ -0,0 +1,171 @@
+package config
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/spf13/viper"
+)
+
+// isolate points the user config at a temporary home, moves into an empty
+// working directory and resets viper, so the tests never read the
+// developer's own config. It returns the user config path and the
+// working directory.
+func isolate(t *testing.T) (userFile, workDir string) {
+    t.Helper()
+    home := t.TempDir()
+    t.Setenv("HOME", home)
+    t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+    for _, env := range os.Environ() {
+        if name, _, _ := strings.Cut(env, "="); strings.HasPrefix(name, "NEXUS_") {
+            t.Setenv(name, "")
+            os.Unsetenv(name)
+        }
+    }
+    workDir = filepath.Join(home, "work")
+    if err := os.MkdirAll(workDir, 0700); err != nil {
+        t.Fatal(err)
+    }
+    chdir(t, workDir)
+    viper.Reset()
+    t.Cleanup(viper.Reset)
+    return filepath.Join(home, ".config", "nexuscli", "config.yaml"), workDir
+}
+
+// chdir changes into dir for the rest of the test.
+func chdir(t *testing.T, dir string) {
+    t.Helper()
+    old, err := os.Getwd()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := os.Chdir(dir); err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { os.Chdir(old) })
+}
+
+func writeTestFile(t *testing.T, path, data string) {
+    t.Helper()
+    if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+        t.Fatal(err)
+    }
+}
+
+const contextsFile = `url: https://nexus.example.com
+timeout: 10
+currentContext: dev
+contexts:
+  dev:
+    url: https://nexus-dev.example.com
+  prod:
+    url: https://nexus-prod.example.com
+    timeout: 60
+`
+
+func TestContexts(t *testing.T) {
+    tests := []struct {
+        name        string
+        env         map[string]string
+        flag        string
+        wantContext string
+        wantURL     string
+        wantTimeout int
+        wantMissing bool
+    }{
+        {
+            name:        "currentContext from the file",
+            wantContext: "dev",
+            wantURL:     "https://nexus-dev.example.com",
+            wantTimeout: 10,
+        },
+        {
+            name:        "NEXUS_CONTEXT beats the file",
+            env:         map[string]string{"NEXUS_CONTEXT": "prod"},
+            wantContext: "prod",
+            wantURL:     "https://nexus-prod.example.com",
+            wantTimeout: 60,
+        },
+        {
+            name:        "--context beats NEXUS_CONTEXT",
+            env:         map[string]string{"NEXUS_CONTEXT": "dev"},
+            flag:        "prod",
+            wantContext: "prod",
+            wantURL:     "https://nexus-prod.example.com",
+            wantTimeout: 60,
+        },
+        {
+            name:        "environment beats the context",
+            env:         map[string]string{"NEXUS_CONTEXT": "prod", "NEXUS_TIMEOUT": "5"},
+            wantContext: "prod",
+            wantURL:     "https://nexus-prod.example.com",
+            wantTimeout: 5,
+        },
+        {
+            name:        "missing context",
+            flag:        "staging",
+            wantContext: "staging",
+            wantURL:     "https://nexus.example.com",
+            wantTimeout: 10,
+            wantMissing: true,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            userFile, _ := isolate(t)
+            writeTestFile(t, userFile, contextsFile)
+            for k, v := range tt.env {
+                t.Setenv(k, v)
+            }
+            if tt.flag != "" {
+                viper.Set("context", tt.flag)
+            }
+
+            if err := InitViper(); err != nil {
+                t.Fatal(err)
+            }
+            if ActiveContext != tt.wantContext {
+                t.Errorf("ActiveContext = %q, want %q", ActiveContext, tt.wantContext)
+            }
+            if Global.URL != tt.wantURL || Global.Timeout != tt.wantTimeout {
+                t.Errorf("got url %q timeout %d, want %q %d", Global.URL, Global.Timeout, tt.wantURL, tt.wantTimeout)
+            }
+            if err := CheckContext(); errors.Is(err, ErrContextNotFound) != tt.wantMissing {
+                t.Errorf("CheckContext() = %v", err)
+            }
+        })
+    }
+}
+
+func TestSource(t *testing.T) {
+    userFile, _ := isolate(t)
+    writeTestFile(t, userFile, contextsFile)
+    t.Setenv("NEXUS_RETRIES", "1")
+    if err := InitViper(); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        key         string
+        flagChanged bool
+        want        string
+    }{
+        {"url", true, "flag"},
+        {"retries", false, "env NEXUS_RETRIES"},
+        {"url", false, "file " + userFile + " (context dev)"},
+        {"timeout", false, "file " + userFile},
+        {"logFormat", false, "default"},
+    }
+    for _, tt := range tests {
+        if got := Source(tt.key, tt.flagChanged); got != tt.want {
+            t.Errorf("Source(%q, %v) = %q, want %q", tt.key, tt.flagChanged, got, tt.want)
+        }
+    }
+}
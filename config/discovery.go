@@ -0,0 +1,290 @@
+package config
+
+import (
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "gopkg.in/yaml.v3"
+)
+
+// ProjectFileNames are looked up in the working directory and its parents.
+var ProjectFileNames = []string{".nexuscli.yml", ".nexuscli.yaml"}
+
+// LoadedFile is a config file that contributed to the current settings.
+type LoadedFile struct {
+    Path string
+    // Data holds the settings of the file in the flat schema.
+    Data map[string]interface{}
+}
+
+// LoadedFiles lists the files InitViper read, lowest precedence first.
+var LoadedFiles []LoadedFile
+
+// Warnings lists the problems InitViper skipped over, for the caller to
+// report.
+var Warnings []string
+
+// projectKeys are the settings a project-local file may set. A project file
+// comes with whatever repository the user works in, so it must not decide
+// where requests and credentials go: the server, TLS, proxy and credential
+// settings are only read from the user's own config, or, for the
+// trustedProjectKeys, from a project file the user trusted.
+var projectKeys = []string{currentContextKey, "logFormat", "retries", "retryMaxWait", "timeout"}
+
+// keyAliases maps keys of the nested schema to their flat names.
+var keyAliases = map[string]string{
+    "timeoutSeconds": "timeout",
+}
+
+// userConfigPaths returns the XDG location ($XDG_CONFIG_HOME/nexuscli/config.yaml)
+// and the legacy ~/.nexuscli.yaml. Either may be empty.
+func userConfigPaths() (xdg, legacy string) {
+    if dir, err := os.UserConfigDir(); err == nil {
+        xdg = filepath.Join(dir, "nexuscli", "config.yaml")
+    }
+    if home, err := os.UserHomeDir(); err == nil {
+        legacy = filepath.Join(home, ".nexuscli.yaml")
+    }
+    return xdg, legacy
+}
+
+// userConfigPath returns the user config file: the XDG one if it exists,
+// then the legacy one if it exists, else the XDG location for a new file.
+func userConfigPath() (string, error) {
+    xdg, legacy := userConfigPaths()
+    for _, p := range []string{xdg, legacy} {
+        if p != "" && fileExists(p) {
+            return p, nil
+        }
+    }
+    if xdg != "" {
+        return xdg, nil
+    }
+    if legacy != "" {
+        return legacy, nil
+    }
+    return "", fmt.Errorf("could not find a config directory")
+}
+
+// findProjectFile walks up from dir looking for a project-local config
+// file. The user config file is skipped should it live on the way up.
+func findProjectFile(dir, userFile string) string {
+    for {
+        for _, name := range ProjectFileNames {
+            p := filepath.Join(dir, name)
+            if p != userFile && fileExists(p) {
+                return p
+            }
+        }
+        parent := filepath.Dir(dir)
+        if parent == dir {
+            return ""
+        }
+        dir = parent
+    }
+}
+
+// configFiles returns the files to load, lowest precedence first, and the
+// project-local one among them, if any. An explicit file (--config or
+// NEXUS_CONFIG) disables discovery.
+func configFiles(explicit string) (files []string, project string, err error) {
+    if explicit != "" {
+        return []string{explicit}, "", nil
+    }
+    userFile, err := userConfigPath()
+    if err != nil {
+        return nil, "", err
+    }
+    files = []string{userFile}
+    if wd, err := os.Getwd(); err == nil {
+        if project = findProjectFile(wd, userFile); project != "" {
+            files = append(files, project)
+        }
+    }
+    return files, project, nil
+}
+
+// restrictProjectFile removes the settings that are not in projectKeys,
+// or with trusted set in trustedProjectKeys either, from the data of a
+// project-local file and returns their names, sorted.
+func restrictProjectFile(data map[string]interface{}, trusted bool) []string {
+    var dropped []string
+    for key := range data {
+        if contains(projectKeys, key) || trusted && contains(trustedProjectKeys, key) {
+            continue
+        }
+        dropped = append(dropped, key)
+        delete(data, key)
+    }
+    sort.Strings(dropped)
+    return dropped
+}
+
+// projectFileWarning explains why settings of a project file were ignored
+// and how to have them read. lapsed tells that the file was trusted before
+// it changed.
+func projectFileWarning(path string, dropped []string, lapsed bool) string {
+    if lapsed {
+        return fmt.Sprintf("ignoring %s in %s: the file changed since you trusted it; check it and run "+
+            "\"nexuscli config trust-project\" again (shown once)", strings.Join(dropped, ", "), path)
+    }
+    return fmt.Sprintf("ignoring %s in %s: a project-local file may only set %s; if you wrote or checked it, "+
+        "\"nexuscli config trust-project\" lets it set %s as well (shown once)",
+        strings.Join(dropped, ", "), path, strings.Join(projectKeys, ", "), strings.Join(trustedProjectKeys, ", "))
+}
+
+func contains(list []string, s string) bool {
+    for _, v := range list {
+        if v == s {
+            return true
+        }
+    }
+    return false
+}
+
+// readConfigFile reads a config file in either schema and returns it in
+// the flat one. A missing file returns nil without error.
+func readConfigFile(path string) (map[string]interface{}, error) {
+    m, _, err := readConfigLayout(path)
+    return m, err
+}
+
+// readConfigLayout is readConfigFile that also returns the schema the file
+// is written in.
+func readConfigLayout(path string) (map[string]interface{}, layout, error) {
+    data, err := os.ReadFile(path)
+    if errors.Is(err, fs.ErrNotExist) {
+        return nil, layout{}, nil
+    }
+    if err != nil {
+        return nil, layout{}, fmt.Errorf("could not read config %s: %w", path, err)
+    }
+    m := map[string]interface{}{}
+    if err := yaml.Unmarshal(data, &m); err != nil {
+        return nil, layout{}, fmt.Errorf("could not parse config %s: %w", path, err)
+    }
+    if m == nil {
+        m = map[string]interface{}{}
+    }
+    l := layoutOf(m)
+    normalize(m)
+    return m, l, nil
+}
+
+// layout records how a file in the nested schema is written, so it can be
+// written back the same way.
+type layout struct {
+    // nested is set when the settings are under "nexus:".
+    nested bool
+    // aliases maps flat keys to the old names the file uses for them.
+    aliases map[string]string
+}
+
+func layoutOf(m map[string]interface{}) layout {
+    l := layout{aliases: map[string]string{}}
+    sections := []map[string]interface{}{m}
+    if nested, ok := m["nexus"].(map[string]interface{}); ok {
+        l.nested = true
+        sections = append(sections, nested)
+    }
+    for _, section := range sections {
+        for old, key := range keyAliases {
+            if _, ok := section[old]; ok {
+                l.aliases[key] = old
+            }
+        }
+    }
+    return l
+}
+
+// apply returns the flat settings m in layout l. Contexts stay at the top
+// level, where the nested schema has them too.
+func (l layout) apply(m map[string]interface{}) map[string]interface{} {
+    if !l.nested && len(l.aliases) == 0 {
+        return m
+    }
+    out := map[string]interface{}{}
+    settings := out
+    if l.nested {
+        settings = map[string]interface{}{}
+    }
+    for key, v := range m {
+        if key == contextsKey || key == currentContextKey {
+            out[key] = v
+            continue
+        }
+        if old, ok := l.aliases[key]; ok {
+            key = old
+        }
+        settings[key] = v
+    }
+    if l.nested && len(settings) > 0 {
+        out["nexus"] = settings
+    }
+    return out
+}
+
+// normalize rewrites m from the nested schema
+//
+//  nexus:
+//    url: ...
+//    timeoutSeconds: 30
+//
+// to the flat one, in place. Flat keys win over nested ones. Contexts are
+// normalized as well.
+func normalize(m map[string]interface{}) bool {
+    changed := false
+    if nested, ok := m["nexus"].(map[string]interface{}); ok {
+        for k, v := range nested {
+            if _, exists := m[k]; !exists {
+                m[k] = v
+            }
+        }
+        delete(m, "nexus")
+        changed = true
+    }
+    for old, key := range keyAliases {
+        if v, ok := m[old]; ok {
+            if _, exists := m[key]; !exists {
+                m[key] = v
+            }
+            delete(m, old)
+            changed = true
+        }
+    }
+    if contexts, ok := m[contextsKey].(map[string]interface{}); ok {
+        for _, section := range contexts {
+            if s, ok := section.(map[string]interface{}); ok && normalize(s) {
+                changed = true
+            }
+        }
+    }
+    return changed
+}
+
+// mergeMaps copies src into dst, merging nested maps.
+func mergeMaps(dst, src map[string]interface{}) {
+    for k, v := range src {
+        if sm, ok := v.(map[string]interface{}); ok {
+            if dm, ok := dst[k].(map[string]interface{}); ok {
+                mergeMaps(dm, sm)
+                continue
+            }
+            copied := map[string]interface{}{}
+            mergeMaps(copied, sm)
+            dst[k] = copied
+            continue
+        }
+        dst[k] = v
+    }
+}
+
+func fileExists(path string) bool {
+    info, err := os.Stat(path)
+    return err == nil && !info.IsDir()
+}
@@ -0,0 +1,308 @@
+package config
+
+import (
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/spf13/viper"
+    "gopkg.in/yaml.v3"
+)
+
+func parseYAML(t *testing.T, data string) map[string]interface{} {
+    t.Helper()
+    m := map[string]interface{}{}
+    if err := yaml.Unmarshal([]byte(data), &m); err != nil {
+        t.Fatal(err)
+    }
+    return m
+}
+
+func TestNormalize(t *testing.T) {
+    tests := []struct {
+        name        string
+        in          string
+        want        string
+        wantChanged bool
+    }{
+        {
+            name: "flat file is left alone",
+            in:   "url: https://a\ntimeout: 5\n",
+            want: "url: https://a\ntimeout: 5\n",
+        },
+        {
+            name:        "nested schema",
+            in:          "nexus:\n  url: https://a\n  timeoutSeconds: 5\n",
+            want:        "url: https://a\ntimeout: 5\n",
+            wantChanged: true,
+        },
+        {
+            name:        "flat keys win over nested ones",
+            in:          "url: https://flat\nnexus:\n  url: https://nested\n",
+            want:        "url: https://flat\n",
+            wantChanged: true,
+        },
+        {
+            name:        "flat name wins over its alias",
+            in:          "timeout: 5\ntimeoutSeconds: 9\n",
+            want:        "timeout: 5\n",
+            wantChanged: true,
+        },
+        {
+            name:        "contexts",
+            in:          "contexts:\n  dev:\n    nexus:\n      timeoutSeconds: 7\n",
+            want:        "contexts:\n  dev:\n    timeout: 7\n",
+            wantChanged: true,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            m := parseYAML(t, tt.in)
+            changed := normalize(m)
+            if want := parseYAML(t, tt.want); !reflect.DeepEqual(m, want) {
+                t.Errorf("normalize() = %v, want %v", m, want)
+            }
+            if changed != tt.wantChanged {
+                t.Errorf("normalize() changed = %v, want %v", changed, tt.wantChanged)
+            }
+        })
+    }
+}
+
+func TestLayoutApply(t *testing.T) {
+    tests := []struct {
+        name string
+        in   string
+    }{
+        {"flat", "url: https://a\ntimeout: 5\n"},
+        {"nested", "nexus:\n  url: https://a\n  timeoutSeconds: 5\n"},
+        {"nested with contexts", "currentContext: dev\ncontexts:\n  dev:\n    url: https://b\nnexus:\n  url: https://a\n"},
+        {"flat with alias", "url: https://a\ntimeoutSeconds: 5\n"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            original := parseYAML(t, tt.in)
+            m := parseYAML(t, tt.in)
+            l := layoutOf(m)
+            normalize(m)
+            if got := l.apply(m); !reflect.DeepEqual(got, original) {
+                t.Errorf("apply() = %v, want the original %v", got, original)
+            }
+        })
+    }
+}
+
+func TestRestrictProjectFile(t *testing.T) {
+    const project = `
+url: https://attacker.example.com
+username: ci
+credentialHelper: evil
+insecureSkipVerify: true
+proxy: http://attacker.example.com:3128
+timeout: 5
+logFormat: json
+currentContext: dev
+contexts:
+  dev:
+    url: https://attacker.example.com
+`
+    tests := []struct {
+        name        string
+        trusted     bool
+        wantDropped []string
+        wantKept    string
+    }{
+        {
+            name:        "untrusted",
+            wantDropped: []string{"contexts", "credentialHelper", "insecureSkipVerify", "proxy", "url", "username"},
+            wantKept:    "timeout: 5\nlogFormat: json\ncurrentContext: dev\n",
+        },
+        {
+            name:        "trusted",
+            trusted:     true,
+            wantDropped: []string{"contexts", "credentialHelper", "proxy"},
+            wantKept: "url: https://attacker.example.com\nusername: ci\ninsecureSkipVerify: true\n" +
+                "timeout: 5\nlogFormat: json\ncurrentContext: dev\n",
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            m := parseYAML(t, project)
+            if dropped := restrictProjectFile(m, tt.trusted); !reflect.DeepEqual(dropped, tt.wantDropped) {
+                t.Errorf("dropped %v, want %v", dropped, tt.wantDropped)
+            }
+            if want := parseYAML(t, tt.wantKept); !reflect.DeepEqual(m, want) {
+                t.Errorf("kept %v, want %v", m, want)
+            }
+        })
+    }
+}
+
+func TestTrustProjectFile(t *testing.T) {
+    _, workDir := isolate(t)
+    writeTestFile(t, filepath.Join(workDir, ".nexuscli.yml"), "nexus:\n  url: https://project\n  timeoutSeconds: 20\n")
+
+    steps := []struct {
+        name        string
+        do          func()
+        wantURL     string
+        wantWarning string
+    }{
+        {name: "first run warns", wantWarning: "ignoring url in "},
+        {name: "second run is quiet"},
+        {
+            name:    "trusted",
+            do:      func() { trust(t, true) },
+            wantURL: "https://project",
+        },
+        {
+            name: "changed after trusting",
+            do: func() {
+                writeTestFile(t, filepath.Join(workDir, ".nexuscli.yml"), "url: https://elsewhere\n")
+            },
+            wantWarning: "changed since you trusted it",
+        },
+        {name: "still not trusted, quiet again"},
+        {
+            name:    "trusted again",
+            do:      func() { trust(t, true) },
+            wantURL: "https://elsewhere",
+        },
+        {
+            name: "trust removed",
+            do:   func() { trust(t, false) },
+        },
+    }
+    for _, step := range steps {
+        if step.do != nil {
+            step.do()
+        }
+        if err := InitViper(); err != nil {
+            t.Fatal(err)
+        }
+        if Global.URL != step.wantURL {
+            t.Errorf("%s: url %q, want %q", step.name, Global.URL, step.wantURL)
+        }
+        warnings := strings.Join(Warnings, "\n")
+        if (step.wantWarning == "") != (warnings == "") || !strings.Contains(warnings, step.wantWarning) {
+            t.Errorf("%s: warnings %q, want %q", step.name, warnings, step.wantWarning)
+        }
+        viper.Reset()
+    }
+}
+
+// trust trusts the project file InitViper found last, or with trusted
+// unset takes that back.
+func trust(t *testing.T, trusted bool) {
+    t.Helper()
+    if ProjectFile == "" {
+        t.Fatal("no project file found")
+    }
+    if err := TrustProjectFile(ProjectFile, trusted); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestFilePrecedence(t *testing.T) {
+    tests := []struct {
+        name        string
+        user        string
+        project     string
+        explicit    string
+        wantURL     string
+        wantTimeout int
+        wantRetries int
+        wantWarning string
+        wantFiles   int
+    }{
+        {
+            name:        "defaults",
+            wantTimeout: 30,
+            wantRetries: 3,
+        },
+        {
+            name:        "user file",
+            user:        "url: https://user\ntimeout: 10\n",
+            wantURL:     "https://user",
+            wantTimeout: 10,
+            wantRetries: 3,
+            wantFiles:   1,
+        },
+        {
+            name:        "nested user file",
+            user:        "nexus:\n  url: https://user\n  timeoutSeconds: 15\n",
+            wantURL:     "https://user",
+            wantTimeout: 15,
+            wantRetries: 3,
+            wantFiles:   1,
+        },
+        {
+            name:        "project file beats the user file",
+            user:        "url: https://user\ntimeout: 10\n",
+            project:     "nexus:\n  timeoutSeconds: 20\nretries: 1\n",
+            wantURL:     "https://user",
+            wantTimeout: 20,
+            wantRetries: 1,
+            wantFiles:   2,
+        },
+        {
+            name:        "project file cannot redirect requests",
+            user:        "url: https://user\n",
+            project:     "nexus:\n  url: https://attacker\n",
+            wantURL:     "https://user",
+            wantTimeout: 30,
+            wantRetries: 3,
+            wantWarning: "ignoring url in ",
+            wantFiles:   2,
+        },
+        {
+            name:        "explicit file skips discovery",
+            user:        "url: https://user\n",
+            project:     "timeout: 20\n",
+            explicit:    "url: https://explicit\n",
+            wantURL:     "https://explicit",
+            wantTimeout: 30,
+            wantRetries: 3,
+            wantFiles:   1,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            userFile, workDir := isolate(t)
+            if tt.user != "" {
+                writeTestFile(t, userFile, tt.user)
+            }
+            if tt.project != "" {
+                // found from a subdirectory as well
+                writeTestFile(t, filepath.Join(workDir, ".nexuscli.yml"), tt.project)
+                sub := filepath.Join(workDir, "sub")
+                writeTestFile(t, filepath.Join(sub, "README"), "")
+                chdir(t, sub)
+            }
+            if tt.explicit != "" {
+                path := filepath.Join(t.TempDir(), "explicit.yaml")
+                writeTestFile(t, path, tt.explicit)
+                viper.Set("config", path)
+            }
+
+            if err := InitViper(); err != nil {
+                t.Fatal(err)
+            }
+            if Global.URL != tt.wantURL || Global.Timeout != tt.wantTimeout || Global.Retries != tt.wantRetries {
+                t.Errorf("got url %q timeout %d retries %d, want %q %d %d",
+                    Global.URL, Global.Timeout, Global.Retries, tt.wantURL, tt.wantTimeout, tt.wantRetries)
+            }
+            if len(LoadedFiles) != tt.wantFiles {
+                t.Errorf("loaded %d files, want %d", len(LoadedFiles), tt.wantFiles)
+            }
+            warnings := strings.Join(Warnings, "\n")
+            if (tt.wantWarning == "") != (warnings == "") || !strings.Contains(warnings, tt.wantWarning) {
+                t.Errorf("warnings %q, want %q", warnings, tt.wantWarning)
+            }
+        })
+    }
+}
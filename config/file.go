@@ -0,0 +1,237 @@
+package config
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+
+    "github.com/spf13/viper"
+    "gopkg.in/yaml.v3"
+)
+
+// File is the config file as written by the user. Unlike viper's merged
+// view it holds no defaults, flags or environment values, so it can be
+// edited and written back as is.
+type File struct {
+    Path string
+    Data map[string]interface{}
+
+    // layout is the schema the file was read in; Save keeps it.
+    layout layout
+    // changed lists the settings set since the file was loaded, as
+    // context and key.
+    changed [][2]string
+}
+
+// FilePath returns the config file that "config set" and friends write
+// to: --config/NEXUS_CONFIG if given, otherwise the user config file.
+// Project-local files are never written.
+func FilePath() (string, error) {
+    if p := viper.GetString("config"); p != "" {
+        return p, nil
+    }
+    return userConfigPath()
+}
+
+// LoadFile reads the config file for editing. A missing file yields an
+// empty one; Data is in the flat schema whatever schema the file uses.
+func LoadFile() (*File, error) {
+    path, err := FilePath()
+    if err != nil {
+        return nil, err
+    }
+    data, l, err := readConfigLayout(path)
+    if err != nil {
+        return nil, err
+    }
+    if data == nil {
+        data = map[string]interface{}{}
+    }
+    return &File{Path: path, Data: data, layout: l}, nil
+}
+
+// Save validates the settings changed with Set and writes the file back in
+// the schema it was read in. Problems elsewhere in the file are left for
+// "config doctor" to report, so they do not block an unrelated change.
+func (f *File) Save() error {
+    var problems []string
+    for _, c := range f.changed {
+        value, ok := f.Section(c[0], false)[c[1]]
+        if !ok {
+            continue
+        }
+        if err := validateValue(c[1], value); err != nil {
+            problems = append(problems, c[1]+": "+err.Error())
+        }
+    }
+    if len(problems) > 0 {
+        return &SchemaError{Path: f.Path, Problems: problems}
+    }
+    data, err := yaml.Marshal(f.layout.apply(f.Data))
+    if err != nil {
+        return err
+    }
+    if err := writeFile(f.Path, data); err != nil {
+        return err
+    }
+    f.changed = nil
+    return nil
+}
+
+// ParseFile parses and validates the raw YAML of a config file, in either
+// schema.
+func ParseFile(path string, raw []byte) (*File, error) {
+    m := map[string]interface{}{}
+    if err := yaml.Unmarshal(raw, &m); err != nil {
+        return nil, fmt.Errorf("could not parse config %s: %w", path, err)
+    }
+    if m == nil {
+        m = map[string]interface{}{}
+    }
+    normalize(m)
+    if err := Validate(path, m); err != nil {
+        return nil, err
+    }
+    return &File{Path: path, Data: m}, nil
+}
+
+// WriteRaw validates raw and writes it to the config file as is, keeping
+// the user's comments and layout.
+func WriteRaw(path string, raw []byte) error {
+    if _, err := ParseFile(path, raw); err != nil {
+        return err
+    }
+    return writeFile(path, raw)
+}
+
+// writeFile replaces path atomically: the data goes to a temporary file in
+// the same directory, readable by the owner only, which is then renamed
+// over the old file. A crash never leaves a half-written config behind.
+func writeFile(path string, data []byte) error {
+    dir := filepath.Dir(path)
+    if err := os.MkdirAll(dir, 0700); err != nil {
+        return fmt.Errorf("could not create config dir: %w", err)
+    }
+    tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+    if err != nil {
+        return fmt.Errorf("could not write config: %w", err)
+    }
+    defer os.Remove(tmp.Name())
+
+    if err := tmp.Chmod(0600); err != nil {
+        tmp.Close()
+        return fmt.Errorf("could not write config: %w", err)
+    }
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return fmt.Errorf("could not write config: %w", err)
+    }
+    if err := tmp.Sync(); err != nil {
+        tmp.Close()
+        return fmt.Errorf("could not write config: %w", err)
+    }
+    if err := tmp.Close(); err != nil {
+        return fmt.Errorf("could not write config: %w", err)
+    }
+    if err := os.Rename(tmp.Name(), path); err != nil {
+        return fmt.Errorf("could not write config: %w", err)
+    }
+    return nil
+}
+
+// Section returns the settings of the named context, or the top-level
+// settings when name is empty. With create set a missing context is added.
+func (f *File) Section(name string, create bool) map[string]interface{} {
+    if name == "" {
+        return f.Data
+    }
+    contexts, _ := f.Data[contextsKey].(map[string]interface{})
+    if contexts == nil {
+        if !create {
+            return nil
+        }
+        contexts = map[string]interface{}{}
+        f.Data[contextsKey] = contexts
+    }
+    section, _ := contexts[name].(map[string]interface{})
+    if section == nil && create {
+        section = map[string]interface{}{}
+        contexts[name] = section
+    }
+    return section
+}
+
+// Set stores key in the named context, or at the top level when context is
+// empty.
+func (f *File) Set(context, key string, value interface{}) {
+    f.Section(context, true)[key] = value
+    f.changed = append(f.changed, [2]string{context, key})
+}
+
+// Delete removes key from the named context, or from the top level when
+// context is empty.
+func (f *File) Delete(context, key string) {
+    if section := f.Section(context, false); section != nil {
+        delete(section, key)
+    }
+}
+
+// Contexts returns the names of all contexts, sorted.
+func (f *File) Contexts() []string {
+    contexts, _ := f.Data[contextsKey].(map[string]interface{})
+    names := make([]string, 0, len(contexts))
+    for name := range contexts {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// HasContext reports whether the named context exists.
+func (f *File) HasContext(name string) bool {
+    return f.Section(name, false) != nil
+}
+
+// CurrentContext returns the context selected with use-context.
+func (f *File) CurrentContext() string {
+    name, _ := f.Data[currentContextKey].(string)
+    return name
+}
+
+// SetCurrentContext selects the context used when no override is given.
+func (f *File) SetCurrentContext(name string) {
+    f.Data[currentContextKey] = name
+}
+
+// MigrateFile rewrites the file at path from the nested "nexus:" schema to
+// the flat one. The original is kept next to it with a .bak suffix. It
+// reports whether anything had to change.
+func MigrateFile(path string) (bool, error) {
+    original, err := os.ReadFile(path)
+    if err != nil {
+        return false, fmt.Errorf("could not read config: %w", err)
+    }
+    m := map[string]interface{}{}
+    if err := yaml.Unmarshal(original, &m); err != nil {
+        return false, fmt.Errorf("could not parse config %s: %w", path, err)
+    }
+    if m == nil || !normalize(m) {
+        return false, nil
+    }
+
+    if err := writeFile(path+".bak", original); err != nil {
+        return false, fmt.Errorf("could not write backup: %w", err)
+    }
+    if err := Validate(path, m); err != nil {
+        return false, err
+    }
+    data, err := yaml.Marshal(m)
+    if err != nil {
+        return false, err
+    }
+    if err := writeFile(path, data); err != nil {
+        return false, err
+    }
+    return true, nil
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/spf13/viper"
+)
+
+func TestFileSave(t *testing.T) {
+    tests := []struct {
+        name    string
+        in      string
+        context string
+        key     string
+        value   interface{}
+        want    string
+        wantErr string
+    }{
+        {
+            name:  "flat",
+            in:    "url: https://a\n",
+            key:   "timeout",
+            value: 5,
+            want:  "url: https://a\ntimeout: 5\n",
+        },
+        {
+            name:  "nested schema is kept",
+            in:    "nexus:\n  url: https://a\n  timeoutSeconds: 30\n",
+            key:   "timeout",
+            value: 5,
+            want:  "nexus:\n  url: https://a\n  timeoutSeconds: 5\n",
+        },
+        {
+            name:    "contexts stay at the top level",
+            in:      "nexus:\n  url: https://a\n",
+            context: "dev",
+            key:     "url",
+            value:   "https://dev",
+            want:    "nexus:\n  url: https://a\ncontexts:\n  dev:\n    url: https://dev\n",
+        },
+        {
+            name:  "unrelated problems do not block a change",
+            in:    "url: https://a\nlogFormat: xml\n",
+            key:   "timeout",
+            value: 5,
+            want:  "url: https://a\nlogFormat: xml\ntimeout: 5\n",
+        },
+        {
+            name:    "the changed key is validated",
+            in:      "url: https://a\n",
+            key:     "url",
+            value:   "nexus.example.com",
+            want:    "url: https://a\n",
+            wantErr: "url: ",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            isolate(t)
+            path := filepath.Join(t.TempDir(), "config.yaml")
+            writeTestFile(t, path, tt.in)
+            viper.Set("config", path)
+
+            f, err := LoadFile()
+            if err != nil {
+                t.Fatal(err)
+            }
+            f.Set(tt.context, tt.key, tt.value)
+            err = f.Save()
+            if tt.wantErr != "" {
+                var schemaErr *SchemaError
+                if !errors.As(err, &schemaErr) || !strings.Contains(err.Error(), tt.wantErr) {
+                    t.Fatalf("Save() = %v, want a schema error about %q", err, tt.wantErr)
+                }
+            } else if err != nil {
+                t.Fatal(err)
+            }
+
+            raw, err := os.ReadFile(path)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got, want := parseYAML(t, string(raw)), parseYAML(t, tt.want); !reflect.DeepEqual(got, want) {
+                t.Errorf("file is\n%s\nwant\n%s", raw, tt.want)
+            }
+            if info, _ := os.Stat(path); tt.wantErr == "" && info.Mode().Perm() != 0600 {
+                t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+            }
+        })
+    }
+}
+
+func TestValidate(t *testing.T) {
+    tests := []struct {
+        name string
+        in   string
+        want []string
+    }{
+        {"valid", "url: https://a\ntimeout: 5\ncurrentContext: dev\ncontexts:\n  dev:\n    proxy: direct\n", nil},
+        {"unknown key", "uri: https://a\n", []string{`unknown key "uri"`}},
+        {"url without host", "url: nexus\n", []string{`url: "nexus" has no host`}},
+        {"url scheme", "url: ftp://nexus\n", []string{`url: unsupported scheme "ftp"`}},
+        {"timeout", "timeout: 0\n", []string{"timeout: must be at least 1"}},
+        {"tls version as number", "tlsMinVersion: 1.2\n", nil},
+        {"credential helper path", "credentialHelper: /tmp/evil\n", []string{"credentialHelper: invalid credential helper"}},
+        {"missing context", "currentContext: prod\n", []string{`context "prod" does not exist`}},
+        {"context in context", "contexts:\n  dev:\n    contexts: {}\n", []string{`context "dev": unknown key "contexts"`}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := Validate("c.yaml", parseYAML(t, tt.in))
+            if tt.want == nil {
+                if err != nil {
+                    t.Errorf("Validate() = %v", err)
+                }
+                return
+            }
+            var schemaErr *SchemaError
+            if !errors.As(err, &schemaErr) || len(schemaErr.Problems) != len(tt.want) {
+                t.Fatalf("Validate() = %v, want %d problems", err, len(tt.want))
+            }
+            for i, want := range tt.want {
+                if !strings.Contains(schemaErr.Problems[i], want) {
+                    t.Errorf("problem %q, want %q", schemaErr.Problems[i], want)
+                }
+            }
+        })
+    }
+}
+
+func TestLookupKey(t *testing.T) {
+    tests := []struct {
+        name, want string
+        ok         bool
+    }{
+        {"url", "url", true},
+        {"retry-max-wait", "retryMaxWait", true},
+        {"RETRYMAXWAIT", "retryMaxWait", true},
+        {"timeoutSeconds", "timeout", true},
+        {"insecure-skip-verify", "insecureSkipVerify", true},
+        {"nope", "", false},
+    }
+    for _, tt := range tests {
+        if got, ok := LookupKey(tt.name); got != tt.want || ok != tt.ok {
+            t.Errorf("LookupKey(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+        }
+    }
+}
+
+func TestMigrateFile(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "config.yaml")
+    nested := "nexus:\n  url: https://a\n  timeoutSeconds: 5\n"
+    writeTestFile(t, path, nested)
+
+    changed, err := MigrateFile(path)
+    if err != nil || !changed {
+        t.Fatalf("MigrateFile() = %v, %v", changed, err)
+    }
+    raw, _ := os.ReadFile(path)
+    if got, want := parseYAML(t, string(raw)), parseYAML(t, "url: https://a\ntimeout: 5\n"); !reflect.DeepEqual(got, want) {
+        t.Errorf("migrated file is\n%s", raw)
+    }
+    if backup, _ := os.ReadFile(path + ".bak"); string(backup) != nested {
+        t.Errorf("backup is\n%s", backup)
+    }
+
+    if changed, err := MigrateFile(path); err != nil || changed {
+        t.Errorf("second MigrateFile() = %v, %v, want nothing to do", changed, err)
+    }
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+    "fmt"
+    "os"
+    "strings"
+)
+
+// EnvVar returns the environment variable that sets key, e.g.
+// NEXUS_RETRYMAXWAIT for retryMaxWait.
+func EnvVar(key string) string {
+    return "NEXUS_" + strings.ToUpper(key)
+}
+
+// Source describes where the effective value of key comes from, following
+// the precedence of InitViper: "flag", "env NEXUS_...", "file <path>"
+// (naming the context when the value is set there) or "default".
+// flagChanged tells whether a command line flag bound to key was given.
+func Source(key string, flagChanged bool) string {
+    if flagChanged {
+        return "flag"
+    }
+    if _, ok := os.LookupEnv(EnvVar(key)); ok {
+        return "env " + EnvVar(key)
+    }
+
+    // the active context of any file beats the top level of every file
+    if ActiveContext != "" {
+        for i := len(LoadedFiles) - 1; i >= 0; i-- {
+            contexts, _ := LoadedFiles[i].Data[contextsKey].(map[string]interface{})
+            section, _ := contexts[ActiveContext].(map[string]interface{})
+            if _, ok := section[key]; ok {
+                return fmt.Sprintf("file %s (context %s)", LoadedFiles[i].Path, ActiveContext)
+            }
+        }
+    }
+    for i := len(LoadedFiles) - 1; i >= 0; i-- {
+        if _, ok := LoadedFiles[i].Data[key]; ok {
+            return "file " + LoadedFiles[i].Path
+        }
+    }
+    return "default"
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+)
+
+// trustedProjectKeys are the settings a project-local file may set on top
+// of projectKeys once the user trusted it with "config trust-project".
+var trustedProjectKeys = []string{"insecureSkipVerify", "password", "token", "url", "username"}
+
+// ProjectFile is the project-local file InitViper found, if any.
+var ProjectFile string
+
+// projectState is what the user has seen of and decided about one
+// project-local file.
+type projectState struct {
+    // SHA256 of the contents the entry applies to. Trust lapses, and the
+    // warning about ignored settings is shown again, when it changes.
+    SHA256  string `json:"sha256"`
+    Trusted bool   `json:"trusted"`
+}
+
+// projectStatePath returns the file that records the projectState of every
+// project-local file seen, next to the XDG user config.
+func projectStatePath() string {
+    dir, err := os.UserConfigDir()
+    if err != nil {
+        return ""
+    }
+    return filepath.Join(dir, "nexuscli", "projects.json")
+}
+
+// loadProjectStates reads the recorded states. A missing or damaged file
+// yields none: every project file is untrusted again.
+func loadProjectStates(path string) map[string]projectState {
+    states := map[string]projectState{}
+    if data, err := os.ReadFile(path); err == nil {
+        if err := json.Unmarshal(data, &states); err != nil {
+            return map[string]projectState{}
+        }
+    }
+    return states
+}
+
+func saveProjectStates(path string, states map[string]projectState) error {
+    data, err := json.MarshalIndent(states, "", "  ")
+    if err != nil {
+        return err
+    }
+    return writeFile(path, append(data, '\n'))
+}
+
+func fileSHA256(path string) (string, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return "", err
+    }
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:]), nil
+}
+
+// projectTrust tells whether the project-local file at path is trusted in
+// its current contents, and whether the user was already warned about it.
+// lapsed is set when the file was trusted before it changed. A file seen
+// for the first time is recorded, so its warning is only shown once.
+func projectTrust(path string) (trusted, seen, lapsed bool) {
+    statePath := projectStatePath()
+    sum, err := fileSHA256(path)
+    if statePath == "" || err != nil {
+        return false, false, false
+    }
+    states := loadProjectStates(statePath)
+    state, ok := states[path]
+    if ok && state.SHA256 == sum {
+        return state.Trusted, true, false
+    }
+    states[path] = projectState{SHA256: sum}
+    // without a writable state file the warning is shown on every run
+    _ = saveProjectStates(statePath, states)
+    return false, false, ok && state.Trusted
+}
+
+// TrustProjectFile lets InitViper read the trustedProjectKeys of the
+// project-local file at path as long as it does not change, or with trust
+// unset takes that back.
+func TrustProjectFile(path string, trust bool) error {
+    path, err := filepath.Abs(path)
+    if err != nil {
+        return err
+    }
+    sum, err := fileSHA256(path)
+    if err != nil {
+        return fmt.Errorf("could not read project config: %w", err)
+    }
+    statePath := projectStatePath()
+    if statePath == "" {
+        return fmt.Errorf("could not find a config directory")
+    }
+    states := loadProjectStates(statePath)
+    states[path] = projectState{SHA256: sum, Trusted: trust}
+    return saveProjectStates(statePath, states)
+}
@@ -0,0 +1,261 @@
+package config
+
+import (
+    "fmt"
+    "net/url"
+    "reflect"
+    "sort"
+    "strconv"
+    "strings"
+    "nexuscli/internal/credentials"
+)
+
+// SchemaError lists the problems found in a config file.
+type SchemaError struct {
+    Path     string
+    Problems []string
+}
+
+func (e *SchemaError) Error() string {
+    msg := "invalid config"
+    if e.Path != "" {
+        msg += " " + e.Path
+    }
+    return msg + ":\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Keys returns the settings that may appear in a config file or context,
+// sorted.
+func Keys() []string {
+    t := reflect.TypeOf(Config{})
+    keys := make([]string, 0, t.NumField())
+    for i := 0; i < t.NumField(); i++ {
+        if tag := t.Field(i).Tag.Get("mapstructure"); tag != "" {
+            keys = append(keys, tag)
+        }
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// LookupKey returns the config key named by name, which may also be given
+// in flag form (retry-max-wait) or in any case.
+func LookupKey(name string) (string, bool) {
+    flat := strings.ToLower(strings.ReplaceAll(name, "-", ""))
+    if alias, ok := keyAliases[name]; ok {
+        flat = strings.ToLower(alias)
+    }
+    for _, key := range Keys() {
+        if strings.ToLower(key) == flat {
+            return key, true
+        }
+    }
+    return "", false
+}
+
+// Validate checks the settings of a config file in the flat schema: the
+// top level and every context.
+func Validate(path string, data map[string]interface{}) error {
+    var problems []string
+    known := map[string]bool{contextsKey: true, currentContextKey: true}
+    for _, key := range Keys() {
+        known[key] = true
+    }
+
+    check := func(where string, section map[string]interface{}) {
+        keys := make([]string, 0, len(section))
+        for key := range section {
+            keys = append(keys, key)
+        }
+        sort.Strings(keys)
+        for _, key := range keys {
+            if !known[key] || (where != "" && (key == contextsKey || key == currentContextKey)) {
+                problems = append(problems, fmt.Sprintf("%sunknown key %q", where, key))
+                continue
+            }
+            if key == contextsKey || key == currentContextKey {
+                // checked below
+                continue
+            }
+            if err := validateValue(key, section[key]); err != nil {
+                problems = append(problems, fmt.Sprintf("%s%s: %v", where, key, err))
+            }
+        }
+    }
+
+    check("", data)
+
+    if raw, ok := data[contextsKey]; ok && raw != nil {
+        contexts, ok := raw.(map[string]interface{})
+        if !ok {
+            problems = append(problems, "contexts: must be a mapping of context names to settings")
+        }
+        names := make([]string, 0, len(contexts))
+        for name := range contexts {
+            names = append(names, name)
+        }
+        sort.Strings(names)
+        for _, name := range names {
+            section, ok := contexts[name].(map[string]interface{})
+            if !ok {
+                if contexts[name] != nil {
+                    problems = append(problems, fmt.Sprintf("context %q: must be a mapping of settings", name))
+                }
+                continue
+            }
+            check(fmt.Sprintf("context %q: ", name), section)
+        }
+    }
+    if current, ok := data[currentContextKey]; ok {
+        if name, ok := current.(string); !ok {
+            problems = append(problems, "currentContext: must be a string")
+        } else if name != "" {
+            contexts, _ := data[contextsKey].(map[string]interface{})
+            if _, ok := contexts[name]; !ok {
+                problems = append(problems, fmt.Sprintf("currentContext: context %q does not exist", name))
+            }
+        }
+    }
+
+    if len(problems) > 0 {
+        return &SchemaError{Path: path, Problems: problems}
+    }
+    return nil
+}
+
+// CheckValue validates a single setting before it is stored.
+func CheckValue(key string, value interface{}) error {
+    if err := validateValue(key, value); err != nil {
+        return &SchemaError{Problems: []string{key + ": " + err.Error()}}
+    }
+    return nil
+}
+
+// validateValue checks a single setting.
+func validateValue(key string, value interface{}) error {
+    if value == nil {
+        return nil
+    }
+    switch key {
+    case "url":
+        return validateURL(value, "http", "https")
+    case "proxy":
+        if s, ok := value.(string); ok && strings.EqualFold(s, "direct") {
+            return nil
+        }
+        return validateURL(value, "http", "https", "socks5")
+    case "timeout":
+        return validateInt(value, 1)
+    case "retries", "retryMaxWait":
+        return validateInt(value, 0)
+    case "insecureSkipVerify":
+        if _, ok := value.(bool); !ok {
+            return fmt.Errorf("must be true or false")
+        }
+    case "tlsMinVersion":
+        return validateOneOf(value, "", "1.0", "1.1", "1.2", "1.3")
+    case "logFormat":
+        return validateOneOf(value, "text", "json")
+    case "credentialStore":
+        return validateOneOf(value, "", "keyring", "file", "plain")
+    case "credentialHelper":
+        s, ok := value.(string)
+        if !ok {
+            return fmt.Errorf("must be a string")
+        }
+        if s != "" {
+            return credentials.CheckHelperName(s)
+        }
+    case "noProxy":
+        switch v := value.(type) {
+        case string:
+        case []interface{}:
+            for _, item := range v {
+                if _, ok := item.(string); !ok {
+                    return fmt.Errorf("must be a list of hosts")
+                }
+            }
+        case []string:
+        default:
+            return fmt.Errorf("must be a list of hosts")
+        }
+    default:
+        if _, ok := value.(string); !ok {
+            return fmt.Errorf("must be a string")
+        }
+    }
+    return nil
+}
+
+func validateURL(value interface{}, schemes ...string) error {
+    s, ok := value.(string)
+    if !ok {
+        return fmt.Errorf("must be a string")
+    }
+    if s == "" {
+        return nil
+    }
+    u, err := url.Parse(s)
+    if err != nil {
+        return fmt.Errorf("not a valid URL: %v", err)
+    }
+    if u.Host == "" {
+        return fmt.Errorf("%q has no host; use a full URL such as %s://nexus.example.com", s, schemes[0])
+    }
+    for _, scheme := range schemes {
+        if u.Scheme == scheme {
+            return nil
+        }
+    }
+    return fmt.Errorf("unsupported scheme %q (use %s)", u.Scheme, strings.Join(schemes, " or "))
+}
+
+func validateInt(value interface{}, min int) error {
+    var n int
+    switch v := value.(type) {
+    case int:
+        n = v
+    case int64:
+        n = int(v)
+    case string:
+        i, err := strconv.Atoi(v)
+        if err != nil {
+            return fmt.Errorf("must be a whole number, got %q", v)
+        }
+        n = i
+    default:
+        return fmt.Errorf("must be a whole number, got %v", value)
+    }
+    if n < min {
+        return fmt.Errorf("must be at least %d, got %d", min, n)
+    }
+    return nil
+}
+
+func validateOneOf(value interface{}, allowed ...string) error {
+    var s string
+    switch v := value.(type) {
+    case string:
+        s = v
+    case float64:
+        // yaml decodes an unquoted 1.2 as a number
+        s = strconv.FormatFloat(v, 'f', -1, 64)
+        if !strings.Contains(s, ".") {
+            s += ".0"
+        }
+    default:
+        s = fmt.Sprint(value)
+    }
+    for _, a := range allowed {
+        if s == a {
+            return nil
+        }
+    }
+    var shown []string
+    for _, a := range allowed {
+        if a != "" {
+            shown = append(shown, a)
+        }
+    }
+    return fmt.Errorf("must be one of %s, got %q", strings.Join(shown, ", "), s)
+}
@@ -2,41 +2,88 @@ package client
 
 import (
     "bytes"
+    "context"
+    "crypto/tls"
     "encoding/json"
+    "errors"
     "fmt"
+    "io"
     "io/ioutil"
     "net/http"
+    "net/url"
     "time"
 )
 
 type NexusClient struct {
-    baseURL  string
-    username string
-    password string
-    token    string
-    timeout  time.Duration
-    client   *http.Client
-    verbose  int
+    baseURL   string
+    basePath  string
+    userAgent string
+    username  string
+    password  string
+    token     string
+    timeout   time.Duration
+    client    *http.Client
+    transport *http.Transport
+    verbose   int
+    retry     RetryPolicy
+    logOut    io.Writer
+    logFormat string
+    dumpCurl  bool
+    dryRun    bool
+    tlsOpts   *TLSOptions
+    proxyOpts *ProxyOptions
+    proxyURL  *url.URL
 }
 
+// NewNexusClient creates a client for the Nexus instance at url. Every
+// method takes a context.Context; cancelling it aborts the request in
+// flight as well as any pending retry. Use New for the full set of options.
 func NewNexusClient(url, username, password, token string, timeoutSec, verbose int) *NexusClient {
-    timeout := time.Duration(timeoutSec) * time.Second
-    return &NexusClient{
-        baseURL: url,
-        username: username,
-        password: password,
-        token:   token,
-        timeout: timeout,
-        verbose: verbose,
-        client: &http.Client{
-            Timeout: timeout,
-        },
+    // New only fails on TLS, proxy and log format options, none of which are set here
+    c, _ := New(url,
+        WithBasicAuth(username, password),
+        WithToken(token),
+        WithTimeout(time.Duration(timeoutSec)*time.Second),
+        WithVerbosity(verbose),
+    )
+    return c
+}
+
+// SetRetryPolicy replaces the policy used for transient failures.
+func (c *NexusClient) SetRetryPolicy(p RetryPolicy) {
+    c.retry = p
+}
+
+// BaseURL returns the URL of the Nexus instance, including the base path.
+func (c *NexusClient) BaseURL() string {
+    return c.baseURL + c.basePath
+}
+
+// TLSConfig returns a copy of the TLS settings of the client's transport,
+// or nil when a custom transport is in use.
+func (c *NexusClient) TLSConfig() *tls.Config {
+    if c.transport == nil || c.transport.TLSClientConfig == nil {
+        return nil
     }
+    return c.transport.TLSClientConfig.Clone()
+}
+
+// ProxyFor returns the proxy requests to rawURL go through, or nil when
+// they are sent directly.
+func (c *NexusClient) ProxyFor(rawURL string) (*url.URL, error) {
+    if c.transport == nil || c.transport.Proxy == nil {
+        return nil, nil
+    }
+    req, err := http.NewRequest("GET", rawURL, nil)
+    if err != nil {
+        return nil, err
+    }
+    return c.transport.Proxy(req)
 }
 
 // ---------------- USER ---------------- //
 
-func (c *NexusClient) CreateUser(username, password, firstName, lastName, email string, roles []string) error {
+func (c *NexusClient) CreateUser(ctx context.Context, username, password, firstName, lastName, email string, roles []string) error {
     body := map[string]interface{}{
         "userId":    username,
         "firstName": firstName,
@@ -47,181 +94,347 @@ func (c *NexusClient) CreateUser(username, password, firstName, lastName, email
         "roles":     roles,
     }
 
-    return c.post("/service/rest/v1/security/users", body)
+    return c.post(ctx, "/service/rest/v1/security/users", body)
 }
 
-func (c *NexusClient) DeleteUser(username string) error {
-    return c.delete("/service/rest/v1/security/users/" + username)
+func (c *NexusClient) DeleteUser(ctx context.Context, username string) error {
+    return c.delete(ctx, "/service/rest/v1/security/users/"+url.PathEscape(username))
 }
 
-func (c *NexusClient) ListUsers() ([]map[string]interface{}, error) {
-    data, err := c.get("/service/rest/v1/security/users")
-    if err != nil {
-        return nil, err
-    }
-    var users []map[string]interface{}
-    if err := json.Unmarshal(data, &users); err != nil {
-        return nil, err
-    }
-    return users, nil
+func (c *NexusClient) ListUsers(ctx context.Context, opts ListOptions) *Pager[User] {
+    return newPager[User](ctx, c, "/service/rest/v1/security/users", nil, opts)
 }
 
 // ---------------- REPO ---------------- //
 
-func (c *NexusClient) CreateRepository(repoType, name string) error {
-    body := map[string]interface{}{
-        "name":   name,
-        "online": true,
-        "recipe": fmt.Sprintf("%s-hosted", repoType),
-    }
-    return c.post("/service/rest/v1/repositories/"+repoType+"/hosted", body)
+// CreateRepository creates a hosted repository of the given format with
+// default settings. Formats that need more, such as maven2, docker, apt
+// and yum, must use CreateHostedRepository.
+func (c *NexusClient) CreateRepository(ctx context.Context, repoType, name string) error {
+    online := true
+    return c.CreateHostedRepository(ctx, Repository{
+        Name:   name,
+        Format: repoType,
+        Online: &online,
+        Storage: &Storage{
+            BlobStoreName:               "default",
+            StrictContentTypeValidation: true,
+            WritePolicy:                 "ALLOW_ONCE",
+        },
+    })
+}
+
+// CreateHostedRepository creates a hosted repository of repo.Format from
+// the full configuration in repo.
+func (c *NexusClient) CreateHostedRepository(ctx context.Context, repo Repository) error {
+    repo.Type = "hosted"
+    return c.createRepository(ctx, repo)
 }
 
-func (c *NexusClient) DeleteRepository(name string) error {
-    return c.delete("/service/rest/v1/repositories/" + name)
+// CreateProxyRepository creates a proxy repository of repo.Format from the
+// full configuration in repo.
+func (c *NexusClient) CreateProxyRepository(ctx context.Context, repo Repository) error {
+    repo.Type = "proxy"
+    return c.createRepository(ctx, repo)
 }
 
-func (c *NexusClient) ListRepositories() ([]map[string]interface{}, error) {
-    data, err := c.get("/service/rest/v1/repositories")
+// CreateGroupRepository creates a group repository of repo.Format. The
+// order of repo.Group.MemberNames is the order Nexus searches them in.
+func (c *NexusClient) CreateGroupRepository(ctx context.Context, repo Repository) error {
+    repo.Type = "group"
+    return c.createRepository(ctx, repo)
+}
+
+// GetRepository returns the full configuration of a repository. The
+// summary endpoint tells its format and type, which select the endpoint
+// holding the settings.
+func (c *NexusClient) GetRepository(ctx context.Context, name string) (*Repository, error) {
+    data, err := c.get(ctx, "/service/rest/v1/repositories/"+url.PathEscape(name))
     if err != nil {
         return nil, err
     }
-    var repos []map[string]interface{}
-    if err := json.Unmarshal(data, &repos); err != nil {
+    var summary Repository
+    if err := json.Unmarshal(data, &summary); err != nil {
         return nil, err
     }
-    return repos, nil
-}
 
-// ---------------- BLOB ---------------- //
-
-func (c *NexusClient) ListBlobStores() ([]map[string]interface{}, error) {
-    data, err := c.get("/service/rest/v1/blobstores")
+    data, err = c.get(ctx, repositoryPath(summary.Format, summary.Type, name))
     if err != nil {
         return nil, err
     }
-    var blobs []map[string]interface{}
-    if err := json.Unmarshal(data, &blobs); err != nil {
+    var repo Repository
+    if err := json.Unmarshal(data, &repo); err != nil {
         return nil, err
     }
-    return blobs, nil
+    if repo.Format == "" {
+        repo.Format = summary.Format
+    }
+    if repo.Type == "" {
+        repo.Type = summary.Type
+    }
+    if repo.URL == "" {
+        repo.URL = summary.URL
+    }
+    return &repo, nil
+}
+
+// UpdateRepository replaces the configuration of repo.Name with repo.
+// Read it with GetRepository first: settings left out are reset.
+func (c *NexusClient) UpdateRepository(ctx context.Context, repo Repository) error {
+    path := repositoryPath(repo.Format, repo.Type, repo.Name)
+    repo.Format, repo.Type, repo.URL = "", "", ""
+    _, err := c.do(ctx, "PUT", path, repo)
+    return err
+}
+
+func repositoryPath(format, repoType, name string) string {
+    return "/service/rest/v1/repositories/" + apiFormat(format) + "/" + repoType + "/" + url.PathEscape(name)
+}
+
+// createRepository posts repo to the endpoint of its format and type.
+// Format and type are part of the path, not of the body.
+func (c *NexusClient) createRepository(ctx context.Context, repo Repository) error {
+    path := "/service/rest/v1/repositories/" + apiFormat(repo.Format) + "/" + repo.Type
+    repo.Format, repo.Type, repo.URL = "", "", ""
+    _, err := c.do(ctx, "POST", path, repo)
+    return err
 }
 
-func (c *NexusClient) CreateBlobStore(name, path string) error {
+// apiFormat maps a repository format to its name in the API paths: Nexus
+// reports Maven repositories as maven2 but serves them under /maven.
+func apiFormat(format string) string {
+    if format == "maven2" {
+        return "maven"
+    }
+    return format
+}
+
+func (c *NexusClient) DeleteRepository(ctx context.Context, name string) error {
+    return c.delete(ctx, "/service/rest/v1/repositories/"+url.PathEscape(name))
+}
+
+// RebuildIndex schedules a rebuild of the search index of a repository.
+func (c *NexusClient) RebuildIndex(ctx context.Context, name string) error {
+    _, err := c.do(ctx, "POST", "/service/rest/v1/repositories/"+url.PathEscape(name)+"/rebuild-index", nil)
+    return err
+}
+
+// InvalidateCache drops the cached content and metadata of a proxy or
+// group repository, so they are fetched again from the remote.
+func (c *NexusClient) InvalidateCache(ctx context.Context, name string) error {
+    _, err := c.do(ctx, "POST", "/service/rest/v1/repositories/"+url.PathEscape(name)+"/invalidate-cache", nil)
+    return err
+}
+
+func (c *NexusClient) ListRepositories(ctx context.Context, opts ListOptions) *Pager[Repository] {
+    return newPager[Repository](ctx, c, "/service/rest/v1/repositories", nil, opts)
+}
+
+// ---------------- BLOB ---------------- //
+
+func (c *NexusClient) ListBlobStores(ctx context.Context, opts ListOptions) *Pager[BlobStore] {
+    return newPager[BlobStore](ctx, c, "/service/rest/v1/blobstores", nil, opts)
+}
+
+func (c *NexusClient) CreateBlobStore(ctx context.Context, name, path string) error {
     body := map[string]interface{}{
         "name": name,
         "path": path,
     }
-    return c.post("/service/rest/v1/blobstores/file", body)
+    return c.post(ctx, "/service/rest/v1/blobstores/file", body)
 }
 
-func (c *NexusClient) DeleteBlobStore(name string) error {
-    return c.delete("/service/rest/v1/blobstores/" + name)
+func (c *NexusClient) DeleteBlobStore(ctx context.Context, name string) error {
+    return c.delete(ctx, "/service/rest/v1/blobstores/"+url.PathEscape(name))
 }
 
-// ---------------- LOW LEVEL ---------------- //
+// ---------------- COMPONENT ---------------- //
 
-func (c *NexusClient) addAuth(req *http.Request) {
-    if c.token != "" {
-        req.Header.Set("Authorization", "Bearer "+c.token)
-    }else if c.username != "" && c.password != "" {
-        req.SetBasicAuth(c.username, c.password)
-    }
+func (c *NexusClient) ListComponents(ctx context.Context, repository string, opts ListOptions) *Pager[Component] {
+    query := url.Values{"repository": {repository}}
+    return newPager[Component](ctx, c, "/service/rest/v1/components", query, opts)
 }
 
-func (c *NexusClient) logRequest(req *http.Request, body []byte) {
-    if c.verbose >= 1 {
-        fmt.Printf("[HTTP] %s %s\n", req.Method, req.URL.String())
+// GetComponent returns a component and its assets by ID.
+func (c *NexusClient) GetComponent(ctx context.Context, id string) (*Component, error) {
+    data, err := c.get(ctx, "/service/rest/v1/components/"+url.PathEscape(id))
+    if err != nil {
+        return nil, err
     }
-    if c.verbose >= 2 {
-        fmt.Println("Headers:")
-        for k, v := range req.Header {
-            fmt.Printf("  %s: %v\n", k, v)
-        }
-        if len(body) > 0 {
-            fmt.Println("Body:")
-            fmt.Println(string(body))
-        }
+    var component Component
+    if err := json.Unmarshal(data, &component); err != nil {
+        return nil, err
     }
+    return &component, nil
 }
 
-func (c *NexusClient) logResponse(resp *http.Response, data []byte) {
-    if c.verbose >= 1 {
-        fmt.Printf("[HTTP] Response %s\n", resp.Status)
-    }
-    if c.verbose >= 2 {
-        fmt.Println("Response Headers:")
-        for k, v := range resp.Header {
-            fmt.Printf("  %s: %v\n", k, v)
-        }
-        if len(data) > 0 {
-            fmt.Println("Response Body:")
-            fmt.Println(string(data))
-        }
+func (c *NexusClient) ListAssets(ctx context.Context, repository string, opts ListOptions) *Pager[Asset] {
+    query := url.Values{"repository": {repository}}
+    return newPager[Asset](ctx, c, "/service/rest/v1/assets", query, opts)
+}
+
+// Search runs a component search. query holds the Nexus search parameters
+// (repository, format, group, name, version, q, ...).
+func (c *NexusClient) Search(ctx context.Context, query url.Values, opts ListOptions) *Pager[Component] {
+    return newPager[Component](ctx, c, "/service/rest/v1/search", query, opts)
+}
+
+// ---------------- TASK ---------------- //
+
+func (c *NexusClient) ListTasks(ctx context.Context, taskType string, opts ListOptions) *Pager[Task] {
+    query := url.Values{}
+    if taskType != "" {
+        query.Set("type", taskType)
     }
+    return newPager[Task](ctx, c, "/service/rest/v1/tasks", query, opts)
 }
 
-func (c *NexusClient) get(path string) ([]byte, error) {
-    req, err := http.NewRequest("GET", c.baseURL+path, nil)
+// ---------------- STATUS ---------------- //
+
+// CheckStatus verifies the client's credentials against the system status
+// checks, which need an authenticated user, and reports the server version
+// and edition.
+func (c *NexusClient) CheckStatus(ctx context.Context) (*ServerInfo, error) {
+    resp, data, err := c.doResponse(ctx, "GET", "/service/rest/v1/status/check", nil)
     if err != nil {
         return nil, err
     }
-    c.addAuth(req)
-    c.logRequest(req, nil)
+    info := parseServerHeader(resp.Header.Get("Server"))
+    if len(data) > 0 {
+        if err := json.Unmarshal(data, &info.Checks); err != nil {
+            return nil, fmt.Errorf("invalid status response: %w", err)
+        }
+    }
+    return info, nil
+}
 
-    resp, err := c.client.Do(req)
+// ServerInfo reports the server version and edition without checking the
+// credentials. The status endpoint is readable anonymously.
+func (c *NexusClient) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+    resp, _, err := c.doResponse(ctx, "GET", "/service/rest/v1/status", nil)
     if err != nil {
         return nil, err
     }
-    defer resp.Body.Close()
-    data, _ := ioutil.ReadAll(resp.Body)
-
-    c.logResponse(resp, data)
-    return data, nil
+    return parseServerHeader(resp.Header.Get("Server")), nil
 }
 
-func (c *NexusClient) post(path string, body map[string]interface{}) error {
-    data, _ := json.Marshal(body)
-    req, err := http.NewRequest("POST", c.baseURL+path, bytes.NewBuffer(data))
+// Writable reports whether the node accepts writes. A read-only node
+// answers 503, so the request is not retried.
+func (c *NexusClient) Writable(ctx context.Context) (bool, error) {
+    resp, data, err := c.send(ctx, "GET", "/service/rest/v1/status/writable", nil, false)
     if err != nil {
-        return err
+        return false, err
     }
-    c.addAuth(req)
-    req.Header.Set("Content-Type", "application/json")
-    c.logRequest(req, data)
+    switch {
+    case resp.StatusCode == http.StatusServiceUnavailable:
+        return false, nil
+    case resp.StatusCode < 200 || resp.StatusCode >= 300:
+        return false, newAPIError("GET", "/service/rest/v1/status/writable", resp, data)
+    }
+    return true, nil
+}
 
-    resp, err := c.client.Do(req)
-    if err != nil {
-        return err
+// ---------------- LOW LEVEL ---------------- //
+
+func (c *NexusClient) addAuth(req *http.Request) {
+    if c.token != "" {
+        req.Header.Set("Authorization", "Bearer "+c.token)
+    }else if c.username != "" && c.password != "" {
+        req.SetBasicAuth(c.username, c.password)
     }
-    defer resp.Body.Close()
-    respData, _ := ioutil.ReadAll(resp.Body)
+}
+
+func (c *NexusClient) get(ctx context.Context, path string) ([]byte, error) {
+    return c.do(ctx, "GET", path, nil)
+}
+
+func (c *NexusClient) post(ctx context.Context, path string, body map[string]interface{}) error {
+    _, err := c.do(ctx, "POST", path, body)
+    return err
+}
+
+func (c *NexusClient) delete(ctx context.Context, path string) error {
+    _, err := c.do(ctx, "DELETE", path, nil)
+    return err
+}
+
+// do sends a request and returns the response body. Transient failures
+// are retried according to the client's RetryPolicy. Any non-2xx status
+// left after that is turned into an *APIError carrying the details Nexus
+// sent back.
+func (c *NexusClient) do(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+    _, data, err := c.doResponse(ctx, method, path, body)
+    return data, err
+}
 
-    c.logResponse(resp, respData)
-    if resp.StatusCode >= 300 {
-        return fmt.Errorf("Nexus returned status %s", resp.Status)
+// doResponse is do for callers that need the response headers as well.
+func (c *NexusClient) doResponse(ctx context.Context, method, path string, body interface{}) (*http.Response, []byte, error) {
+    var data []byte
+    if body != nil {
+        var err error
+        data, err = json.Marshal(body)
+        if err != nil {
+            return nil, nil, err
+        }
+    }
+
+    for attempt := 0; ; attempt++ {
+        resp, respData, err := c.send(ctx, method, path, data, body != nil)
+        if errors.Is(err, ErrDryRun) {
+            return nil, nil, err
+        }
+        if ctx.Err() == nil && c.retry.shouldRetry(method, attempt, resp, err) {
+            wait := c.retry.delay(attempt, resp)
+            c.logRetry(method, path, attempt+1, wait, resp, err)
+            if err := sleep(ctx, wait); err != nil {
+                return nil, nil, err
+            }
+            continue
+        }
+        if err != nil {
+            return nil, nil, err
+        }
+        if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+            return nil, nil, newAPIError(method, path, resp, respData)
+        }
+        return resp, respData, nil
     }
-    return nil
 }
 
-func (c *NexusClient) delete(path string) error {
-    req, err := http.NewRequest("DELETE", c.baseURL+path, nil)
+// send performs a single attempt. The returned response body is already
+// read and closed.
+func (c *NexusClient) send(ctx context.Context, method, path string, data []byte, isJSON bool) (*http.Response, []byte, error) {
+    req, err := http.NewRequestWithContext(ctx, method, c.baseURL+c.basePath+path, bytes.NewReader(data))
     if err != nil {
-        return err
+        return nil, nil, err
     }
     c.addAuth(req)
-    c.logRequest(req, nil)
+    if isJSON {
+        req.Header.Set("Content-Type", "application/json")
+    }
+    req.Header.Set("Accept", "application/json")
+    req.Header.Set("User-Agent", c.userAgent)
+    c.logRequest(req, data)
+    if c.dumpCurl {
+        c.printCurl(req, data)
+    }
+    if c.dryRun {
+        return nil, nil, ErrDryRun
+    }
 
+    start := time.Now()
     resp, err := c.client.Do(req)
     if err != nil {
-        return err
+        c.logError(req, err, time.Since(start))
+        return nil, nil, err
     }
     defer resp.Body.Close()
-    data, _ := ioutil.ReadAll(resp.Body)
-
-    c.logResponse(resp, data)
-    if resp.StatusCode >= 300 {
-        return fmt.Errorf("Nexus returned status %s", resp.Status)
+    respData, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        c.logError(req, err, time.Since(start))
+        return nil, nil, err
     }
-    return nil
+
+    c.logResponse(req, resp, respData, time.Since(start))
+    return resp, respData, nil
 }
@@ -0,0 +1,71 @@
+package client
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestDeleteEscapesNames(t *testing.T) {
+    srv, last := echoServer(t)
+    c, err := New(srv.URL, WithRetryPolicy(RetryPolicy{}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    ctx := context.Background()
+
+    tests := []struct {
+        name string
+        del  func() error
+        want string
+    }{
+        {"user", func() error { return c.DeleteUser(ctx, "jane doe/admin") }, "/service/rest/v1/security/users/jane%20doe%2Fadmin"},
+        {"repository", func() error { return c.DeleteRepository(ctx, "team/releases") }, "/service/rest/v1/repositories/team%2Freleases"},
+        {"blob store", func() error { return c.DeleteBlobStore(ctx, "blobs?x=1") }, "/service/rest/v1/blobstores/blobs%3Fx=1"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if err := tt.del(); err != nil {
+                t.Fatal(err)
+            }
+            r := last()
+            if r.Method != "DELETE" {
+                t.Errorf("method %s, want DELETE", r.Method)
+            }
+            if got := r.URL.EscapedPath(); got != tt.want {
+                t.Errorf("path %s, want %s", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestContextCancellation(t *testing.T) {
+    release := make(chan struct{})
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        select {
+        case <-release:
+        case <-r.Context().Done():
+        }
+    }))
+    defer srv.Close()
+    defer close(release)
+
+    c, err := New(srv.URL)
+    if err != nil {
+        t.Fatal(err)
+    }
+    ctx, cancel := context.WithCancel(context.Background())
+    time.AfterFunc(50*time.Millisecond, cancel)
+
+    start := time.Now()
+    _, err = c.ServerInfo(ctx)
+    if !errors.Is(err, context.Canceled) {
+        t.Fatalf("got %v, want context.Canceled", err)
+    }
+    if d := time.Since(start); d > 5*time.Second {
+        t.Errorf("cancelled request returned after %v", d)
+    }
+}
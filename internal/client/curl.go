@@ -0,0 +1,93 @@
+package client
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "sort"
+    "strings"
+)
+
+// ErrDryRun is returned instead of sending a request when the client only
+// prints the equivalent curl commands.
+var ErrDryRun = errors.New("dry run: request not sent")
+
+// SetCurlDump makes the client print a curl command for every request. With
+// dryRun set the requests are printed but never sent.
+func (c *NexusClient) SetCurlDump(dump, dryRun bool) {
+    c.dumpCurl = dump || dryRun
+    c.dryRun = dryRun
+}
+
+// curlCommand renders req as a ready-to-paste curl command. Credentials are
+// replaced by references to the NEXUS_* environment variables the CLI
+// itself reads, and secret fields in the body are redacted.
+func (c *NexusClient) curlCommand(req *http.Request, body []byte) string {
+    parts := []string{"curl", "-sS", "-X", req.Method, shellQuote(req.URL.String())}
+
+    keys := make([]string, 0, len(req.Header))
+    for k := range req.Header {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for _, k := range keys {
+        if k == "Authorization" {
+            continue
+        }
+        for _, v := range req.Header[k] {
+            parts = append(parts, "-H", shellQuote(k+": "+v))
+        }
+    }
+
+    if c.token != "" {
+        parts = append(parts, "-H", `"Authorization: Bearer $NEXUS_TOKEN"`)
+    } else if c.username != "" && c.password != "" {
+        parts = append(parts, "-u", `"$NEXUS_USERNAME:$NEXUS_PASSWORD"`)
+    }
+
+    if tls := c.tlsOpts; tls != nil {
+        if tls.InsecureSkipVerify {
+            parts = append(parts, "-k")
+        }
+        if tls.CAFile != "" {
+            parts = append(parts, "--cacert", shellQuote(tls.CAFile))
+        }
+        if tls.CertFile != "" {
+            parts = append(parts, "--cert", shellQuote(tls.CertFile), "--key", shellQuote(tls.KeyFile))
+        }
+    }
+
+    if c.proxyOpts != nil {
+        proxy, err := c.proxyOpts.resolve(req, c.proxyURL)
+        switch {
+        case err != nil:
+        case proxy == nil:
+            // only needed when curl would otherwise pick up HTTPS_PROXY
+            if c.proxyOpts.URL == ProxyDirect || matchNoProxy(req.URL.Host, c.proxyOpts.NoProxy) {
+                parts = append(parts, "--noproxy", "'*'")
+            }
+        default:
+            withoutUser := *proxy
+            withoutUser.User = nil
+            parts = append(parts, "--proxy", shellQuote(withoutUser.String()))
+            if proxy.User != nil {
+                parts = append(parts, "--proxy-user", `"$NEXUS_PROXYUSERNAME:$NEXUS_PROXYPASSWORD"`)
+            }
+        }
+    }
+
+    if len(body) > 0 {
+        parts = append(parts, "--data-raw", shellQuote(redactBody(body)))
+    }
+    return strings.Join(parts, " ")
+}
+
+func (c *NexusClient) printCurl(req *http.Request, body []byte) {
+    fmt.Fprintln(c.logWriter(), c.curlCommand(req, body))
+}
+
+// shellQuote wraps s in single quotes for POSIX shells.
+func shellQuote(s string) string {
+    return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
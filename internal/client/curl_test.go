@@ -0,0 +1,86 @@
+package client
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "net/http"
+    "strings"
+    "testing"
+)
+
+func TestCurlCommand(t *testing.T) {
+    tests := []struct {
+        name string
+        opts []Option
+        body string
+        want string
+    }{
+        {
+            name: "basic auth",
+            opts: []Option{WithBasicAuth("admin", "admin123")},
+            want: `curl -sS -X PUT 'https://nexus.example.com/service/rest/v1/x' -H 'Accept: application/json' -u "$NEXUS_USERNAME:$NEXUS_PASSWORD"`,
+        },
+        {
+            name: "token wins",
+            opts: []Option{WithBasicAuth("admin", "admin123"), WithToken("t0k3n")},
+            want: `curl -sS -X PUT 'https://nexus.example.com/service/rest/v1/x' -H 'Accept: application/json' -H "Authorization: Bearer $NEXUS_TOKEN"`,
+        },
+        {
+            name: "body is redacted and quoted",
+            body: `{"name":"it's","password":"s3cr3t"}`,
+            want: `curl -sS -X PUT 'https://nexus.example.com/service/rest/v1/x' -H 'Accept: application/json' --data-raw '{"name":"it'\''s","password":"REDACTED"}'`,
+        },
+        {
+            name: "tls",
+            opts: []Option{WithTLS(TLSOptions{InsecureSkipVerify: true})},
+            want: `curl -sS -X PUT 'https://nexus.example.com/service/rest/v1/x' -H 'Accept: application/json' -k`,
+        },
+        {
+            name: "proxy without its credentials",
+            opts: []Option{WithProxy(ProxyOptions{URL: "http://proxy:3128", Username: "p", Password: "pw"})},
+            want: `curl -sS -X PUT 'https://nexus.example.com/service/rest/v1/x' -H 'Accept: application/json' --proxy 'http://proxy:3128' --proxy-user "$NEXUS_PROXYUSERNAME:$NEXUS_PROXYPASSWORD"`,
+        },
+        {
+            name: "direct",
+            opts: []Option{WithProxy(ProxyOptions{URL: ProxyDirect})},
+            want: `curl -sS -X PUT 'https://nexus.example.com/service/rest/v1/x' -H 'Accept: application/json' --noproxy '*'`,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            c, err := New("https://nexus.example.com", tt.opts...)
+            if err != nil {
+                t.Fatal(err)
+            }
+            req, _ := http.NewRequest("PUT", "https://nexus.example.com/service/rest/v1/x", nil)
+            req.Header.Set("Accept", "application/json")
+            c.addAuth(req)
+            if got := c.curlCommand(req, []byte(tt.body)); got != tt.want {
+                t.Errorf("curlCommand()\n got: %s\nwant: %s", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestDryRun(t *testing.T) {
+    rec := &recorder{respond: func(r *http.Request) (int, string) {
+        return http.StatusOK, ""
+    }}
+    c := newTestClient(t, rec)
+    var out bytes.Buffer
+    c.SetLogOutput(&out)
+    c.SetCurlDump(false, true)
+
+    err := c.DeleteRepository(context.Background(), "maven-releases")
+    if !errors.Is(err, ErrDryRun) {
+        t.Errorf("got %v, want ErrDryRun", err)
+    }
+    if got := rec.requests(); got != 0 {
+        t.Errorf("a dry run sent %d requests", got)
+    }
+    if !strings.HasPrefix(out.String(), "curl -sS -X DELETE ") {
+        t.Errorf("printed %q, want a curl command", out.String())
+    }
+}
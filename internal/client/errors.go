@@ -0,0 +1,146 @@
+package client
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+)
+
+// ValidationError is a single entry of the list Nexus returns when it
+// rejects a request body.
+type ValidationError struct {
+    ID      string `json:"id"`
+    Message string `json:"message"`
+}
+
+// APIError is returned for every response with a non-2xx status.
+type APIError struct {
+    Method     string
+    Path       string
+    StatusCode int
+    Status     string
+    // Message is the error message from the response body, if any.
+    Message string
+    // Validation holds the per-field errors of a rejected request.
+    Validation []ValidationError
+}
+
+func (e *APIError) Error() string {
+    msg := fmt.Sprintf("%s %s: Nexus returned status %s", e.Method, e.Path, e.Status)
+    if e.Message != "" {
+        msg += ": " + e.Message
+    }
+    for _, v := range e.Validation {
+        if v.ID != "" && v.ID != "*" {
+            msg += fmt.Sprintf("\n  - %s: %s", v.ID, v.Message)
+        } else {
+            msg += "\n  - " + v.Message
+        }
+    }
+    return msg
+}
+
+// maxErrorMessage bounds how much of an unstructured body ends up in an error.
+const maxErrorMessage = 512
+
+func newAPIError(method, path string, resp *http.Response, body []byte) *APIError {
+    e := &APIError{
+        Method:     method,
+        Path:       path,
+        StatusCode: resp.StatusCode,
+        Status:     resp.Status,
+    }
+
+    trimmed := bytes.TrimSpace(body)
+    if len(trimmed) == 0 {
+        return e
+    }
+
+    var list []ValidationError
+    if err := json.Unmarshal(trimmed, &list); err == nil {
+        e.Validation = list
+        return e
+    }
+
+    var obj struct {
+        Message string `json:"message"`
+        Error   string `json:"error"`
+    }
+    if err := json.Unmarshal(trimmed, &obj); err == nil {
+        e.Message = obj.Message
+        if e.Message == "" {
+            e.Message = obj.Error
+        }
+        return e
+    }
+
+    // Nexus answers some errors with plain text or an HTML page
+    if !bytes.HasPrefix(trimmed, []byte("<")) {
+        msg := string(trimmed)
+        if len(msg) > maxErrorMessage {
+            msg = msg[:maxErrorMessage] + "..."
+        }
+        e.Message = msg
+    }
+    return e
+}
+
+func statusOf(err error) int {
+    var apiErr *APIError
+    if errors.As(err, &apiErr) {
+        return apiErr.StatusCode
+    }
+    return 0
+}
+
+// IsNotFound reports whether err is a 404 from Nexus.
+func IsNotFound(err error) bool {
+    return statusOf(err) == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether err is a 401 from Nexus.
+func IsUnauthorized(err error) bool {
+    return statusOf(err) == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether err is a 403 from Nexus.
+func IsForbidden(err error) bool {
+    return statusOf(err) == http.StatusForbidden
+}
+
+// IsConflict reports whether err means the object already exists. Nexus
+// uses 409 for some endpoints and a 400 validation error for others.
+func IsConflict(err error) bool {
+    var apiErr *APIError
+    if !errors.As(err, &apiErr) {
+        return false
+    }
+    if apiErr.StatusCode == http.StatusConflict {
+        return true
+    }
+    if apiErr.StatusCode != http.StatusBadRequest {
+        return false
+    }
+    if strings.Contains(strings.ToLower(apiErr.Message), "already exists") {
+        return true
+    }
+    for _, v := range apiErr.Validation {
+        if strings.Contains(strings.ToLower(v.Message), "already exists") {
+            return true
+        }
+    }
+    return false
+}
+
+// IsValidation reports whether Nexus rejected the request as invalid.
+func IsValidation(err error) bool {
+    return statusOf(err) == http.StatusBadRequest && !IsConflict(err)
+}
+
+// IsServerError reports whether err is a 5xx from Nexus.
+func IsServerError(err error) bool {
+    return statusOf(err) >= 500
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+    long := strings.Repeat("x", maxErrorMessage+10)
+    tests := []struct {
+        name           string
+        body           string
+        wantMessage    string
+        wantValidation []ValidationError
+    }{
+        {"empty body", "", "", nil},
+        {"message field", `{"message":"Repository not found"}`, "Repository not found", nil},
+        {"error field", `{"error":"Bad token"}`, "Bad token", nil},
+        {
+            "validation list",
+            `[{"id":"PARAMETER name","message":"must not be empty"}]`,
+            "",
+            []ValidationError{{ID: "PARAMETER name", Message: "must not be empty"}},
+        },
+        {"plain text", "  Repository is read-only\n", "Repository is read-only", nil},
+        {"html page", "<html><body>502</body></html>", "", nil},
+        {"long text is cut", long, long[:maxErrorMessage] + "...", nil},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            resp := &http.Response{StatusCode: 400, Status: "400 Bad Request"}
+            e := newAPIError("POST", "/service/rest/v1/x", resp, []byte(tt.body))
+            if e.StatusCode != 400 || e.Method != "POST" || e.Path != "/service/rest/v1/x" {
+                t.Errorf("request details not kept: %+v", e)
+            }
+            if e.Message != tt.wantMessage {
+                t.Errorf("Message = %q, want %q", e.Message, tt.wantMessage)
+            }
+            if !reflect.DeepEqual(e.Validation, tt.wantValidation) {
+                t.Errorf("Validation = %v, want %v", e.Validation, tt.wantValidation)
+            }
+        })
+    }
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+    e := &APIError{
+        Method: "POST",
+        Path:   "/service/rest/v1/repositories/maven/hosted",
+        Status: "400 Bad Request",
+        Validation: []ValidationError{
+            {ID: "PARAMETER name", Message: "Name is already used"},
+            {ID: "*", Message: "Invalid"},
+        },
+    }
+    want := "POST /service/rest/v1/repositories/maven/hosted: Nexus returned status 400 Bad Request" +
+        "\n  - PARAMETER name: Name is already used" +
+        "\n  - Invalid"
+    if got := e.Error(); got != want {
+        t.Errorf("Error() = %q, want %q", got, want)
+    }
+}
+
+func TestErrorClasses(t *testing.T) {
+    apiErr := func(status int, message string, validation ...string) error {
+        e := &APIError{StatusCode: status, Message: message}
+        for _, v := range validation {
+            e.Validation = append(e.Validation, ValidationError{Message: v})
+        }
+        return fmt.Errorf("wrapped: %w", e)
+    }
+    tests := []struct {
+        name                                               string
+        err                                                error
+        notFound, unauthorized, forbidden, conflict, valid bool
+        server                                             bool
+    }{
+        {name: "404", err: apiErr(404, ""), notFound: true},
+        {name: "401", err: apiErr(401, ""), unauthorized: true},
+        {name: "403", err: apiErr(403, ""), forbidden: true},
+        {name: "409", err: apiErr(409, ""), conflict: true},
+        {name: "400 already exists message", err: apiErr(400, "Repository already exists"), conflict: true},
+        {name: "400 already exists validation", err: apiErr(400, "", "Name already exists"), conflict: true},
+        {name: "400 invalid", err: apiErr(400, "", "must not be empty"), valid: true},
+        {name: "500", err: apiErr(500, ""), server: true},
+        {name: "503", err: apiErr(503, ""), server: true},
+        {name: "not an API error", err: errors.New("connection refused")},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            for _, c := range []struct {
+                fn   func(error) bool
+                name string
+                want bool
+            }{
+                {IsNotFound, "IsNotFound", tt.notFound},
+                {IsUnauthorized, "IsUnauthorized", tt.unauthorized},
+                {IsForbidden, "IsForbidden", tt.forbidden},
+                {IsConflict, "IsConflict", tt.conflict},
+                {IsValidation, "IsValidation", tt.valid},
+                {IsServerError, "IsServerError", tt.server},
+            } {
+                if got := c.fn(tt.err); got != c.want {
+                    t.Errorf("%s() = %v, want %v", c.name, got, c.want)
+                }
+            }
+        })
+    }
+}
+
+func TestClientReturnsAPIError(t *testing.T) {
+    rec := &recorder{respond: func(r *http.Request) (int, string) {
+        return http.StatusNotFound, `{"message":"Repository 'nope' not found"}`
+    }}
+    c := newTestClient(t, rec)
+
+    _, err := c.GetRepository(context.Background(), "nope")
+    var apiErr *APIError
+    if !errors.As(err, &apiErr) {
+        t.Fatalf("got %T %v, want an *APIError", err, err)
+    }
+    if apiErr.Method != "GET" || apiErr.Path != "/service/rest/v1/repositories/nope" {
+        t.Errorf("got %s %s", apiErr.Method, apiErr.Path)
+    }
+    if apiErr.Message != "Repository 'nope' not found" {
+        t.Errorf("Message = %q", apiErr.Message)
+    }
+    if got := rec.requests(); got != 1 {
+        t.Errorf("a 404 was sent %d times, want once", got)
+    }
+}
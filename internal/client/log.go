@@ -0,0 +1,265 @@
+package client
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "regexp"
+    "sort"
+    "strings"
+    "time"
+)
+
+// Log formats accepted by SetLogFormat.
+const (
+    LogFormatText = "text"
+    LogFormatJSON = "json"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveHeaders are never logged verbatim.
+var sensitiveHeaders = map[string]bool{
+    "Authorization":       true,
+    "Proxy-Authorization": true,
+    "Cookie":              true,
+    "Set-Cookie":          true,
+    "Nx-Anti-Csrf-Token":  true,
+    "X-Api-Key":           true,
+}
+
+// sensitiveField matches JSON keys whose values are redacted in bodies.
+var sensitiveField = regexp.MustCompile(`(?i)(password|passphrase|secret|credential|apikey|token|privatekey|keypair|^key$)`)
+
+// notSensitive are names sensitiveField matches that hold no secret.
+var notSensitive = map[string]bool{
+    "continuationToken": true,
+    "credentialStore":   true,
+    "credentialHelper":  true,
+}
+
+// IsSensitive reports whether a JSON field or setting called name holds a
+// secret that must not be shown.
+func IsSensitive(name string) bool {
+    return sensitiveField.MatchString(name) && !notSensitive[name]
+}
+
+// SetLogOutput sends diagnostics to w instead of stderr.
+func (c *NexusClient) SetLogOutput(w io.Writer) {
+    c.logOut = w
+}
+
+// SetLogFormat selects between human readable text and one JSON record per
+// event.
+func (c *NexusClient) SetLogFormat(format string) error {
+    switch strings.ToLower(format) {
+    case "", LogFormatText:
+        c.logFormat = LogFormatText
+    case LogFormatJSON:
+        c.logFormat = LogFormatJSON
+    default:
+        return fmt.Errorf("unsupported log format %q (use text or json)", format)
+    }
+    return nil
+}
+
+func (c *NexusClient) logWriter() io.Writer {
+    if c.logOut == nil {
+        return os.Stderr
+    }
+    return c.logOut
+}
+
+// logRecord writes a structured record in JSON mode.
+func (c *NexusClient) logRecord(event string, fields map[string]interface{}) {
+    fields["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+    fields["event"] = event
+    data, err := json.Marshal(fields)
+    if err != nil {
+        return
+    }
+    fmt.Fprintln(c.logWriter(), string(data))
+}
+
+func (c *NexusClient) logRequest(req *http.Request, body []byte) {
+    if c.verbose < 1 {
+        return
+    }
+    if c.logFormat == LogFormatJSON {
+        fields := map[string]interface{}{
+            "method": req.Method,
+            "url":    req.URL.String(),
+        }
+        if c.verbose >= 2 {
+            fields["headers"] = redactHeaders(req.Header)
+            if len(body) > 0 {
+                fields["body"] = bodyField(body)
+            }
+        }
+        c.logRecord("request", fields)
+        return
+    }
+
+    w := c.logWriter()
+    fmt.Fprintf(w, "[HTTP] %s %s\n", req.Method, req.URL.String())
+    if c.verbose >= 2 {
+        fmt.Fprintln(w, "Headers:")
+        printHeaders(w, req.Header)
+        if len(body) > 0 {
+            fmt.Fprintln(w, "Body:")
+            fmt.Fprintln(w, redactBody(body))
+        }
+    }
+}
+
+func (c *NexusClient) logResponse(req *http.Request, resp *http.Response, data []byte, elapsed time.Duration) {
+    if c.verbose < 1 {
+        return
+    }
+    if c.logFormat == LogFormatJSON {
+        fields := map[string]interface{}{
+            "method":      req.Method,
+            "url":         req.URL.String(),
+            "status":      resp.StatusCode,
+            "duration_ms": elapsed.Milliseconds(),
+            "bytes":       len(data),
+        }
+        if c.verbose >= 2 {
+            fields["headers"] = redactHeaders(resp.Header)
+            if len(data) > 0 {
+                fields["body"] = bodyField(data)
+            }
+        }
+        c.logRecord("response", fields)
+        return
+    }
+
+    w := c.logWriter()
+    fmt.Fprintf(w, "[HTTP] Response %s (%s)\n", resp.Status, elapsed.Round(time.Millisecond))
+    if c.verbose >= 2 {
+        fmt.Fprintln(w, "Response Headers:")
+        printHeaders(w, resp.Header)
+        if len(data) > 0 {
+            fmt.Fprintln(w, "Response Body:")
+            fmt.Fprintln(w, redactBody(data))
+        }
+    }
+}
+
+func (c *NexusClient) logError(req *http.Request, err error, elapsed time.Duration) {
+    if c.verbose < 1 {
+        return
+    }
+    if c.logFormat == LogFormatJSON {
+        c.logRecord("error", map[string]interface{}{
+            "method":      req.Method,
+            "url":         req.URL.String(),
+            "error":       err.Error(),
+            "duration_ms": elapsed.Milliseconds(),
+        })
+        return
+    }
+    fmt.Fprintf(c.logWriter(), "[HTTP] Error %v (%s)\n", err, elapsed.Round(time.Millisecond))
+}
+
+func (c *NexusClient) logRetry(method, path string, attempt int, wait time.Duration, resp *http.Response, err error) {
+    if c.verbose < 1 {
+        return
+    }
+    reason := ""
+    if err != nil {
+        reason = err.Error()
+    } else {
+        reason = resp.Status
+    }
+    if c.logFormat == LogFormatJSON {
+        c.logRecord("retry", map[string]interface{}{
+            "method":  method,
+            "path":    path,
+            "attempt": attempt,
+            "max":     c.retry.MaxRetries,
+            "wait_ms": wait.Milliseconds(),
+            "reason":  reason,
+        })
+        return
+    }
+    fmt.Fprintf(c.logWriter(), "[HTTP] Retrying %s %s in %s (retry %d/%d): %s\n",
+        method, path, wait.Round(time.Millisecond), attempt, c.retry.MaxRetries, reason)
+}
+
+func printHeaders(w io.Writer, h http.Header) {
+    redactedHeaders := redactHeaders(h)
+    keys := make([]string, 0, len(redactedHeaders))
+    for k := range redactedHeaders {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for _, k := range keys {
+        fmt.Fprintf(w, "  %s: %v\n", k, redactedHeaders[k])
+    }
+}
+
+// redactHeaders returns a copy of h with credentials replaced. The auth
+// scheme is kept so Basic and Bearer can still be told apart.
+func redactHeaders(h http.Header) map[string][]string {
+    out := make(map[string][]string, len(h))
+    for k, values := range h {
+        if !sensitiveHeaders[http.CanonicalHeaderKey(k)] {
+            out[k] = values
+            continue
+        }
+        masked := make([]string, len(values))
+        for i, v := range values {
+            if scheme, _, ok := strings.Cut(v, " "); ok && !strings.Contains(scheme, "=") {
+                masked[i] = scheme + " " + redacted
+            } else {
+                masked[i] = redacted
+            }
+        }
+        out[k] = masked
+    }
+    return out
+}
+
+// redactBody masks secret fields of a JSON body. Other bodies are
+// returned as they are.
+func redactBody(body []byte) string {
+    var v interface{}
+    if err := json.Unmarshal(body, &v); err != nil {
+        return string(body)
+    }
+    data, err := json.Marshal(redactValue(v))
+    if err != nil {
+        return string(body)
+    }
+    return string(data)
+}
+
+// bodyField embeds a redacted JSON body as JSON rather than as a string.
+func bodyField(body []byte) interface{} {
+    b := redactBody(body)
+    if json.Valid([]byte(b)) {
+        return json.RawMessage(b)
+    }
+    return b
+}
+
+func redactValue(v interface{}) interface{} {
+    switch t := v.(type) {
+    case map[string]interface{}:
+        for k, val := range t {
+            if _, isString := val.(string); isString && IsSensitive(k) {
+                t[k] = redacted
+                continue
+            }
+            t[k] = redactValue(val)
+        }
+    case []interface{}:
+        for i, val := range t {
+            t[i] = redactValue(val)
+        }
+    }
+    return v
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "net/http"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestIsSensitive(t *testing.T) {
+    tests := []struct {
+        name string
+        want bool
+    }{
+        {"password", true},
+        {"Password", true},
+        {"proxyPassword", true},
+        {"passphrase", true},
+        {"clientSecret", true},
+        {"credentials", true},
+        {"apiKey", true},
+        {"token", true},
+        {"bearerToken", true},
+        {"accessToken", true},
+        {"privateKey", true},
+        {"keypair", true},
+        {"key", true},
+        {"continuationToken", false},
+        {"credentialStore", false},
+        {"credentialHelper", false},
+        {"keyFile", false},
+        {"blobStoreName", false},
+        {"username", false},
+    }
+    for _, tt := range tests {
+        if got := IsSensitive(tt.name); got != tt.want {
+            t.Errorf("IsSensitive(%q) = %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestRedactBody(t *testing.T) {
+    tests := []struct {
+        name string
+        body string
+        want string
+    }{
+        {"not JSON", "user=admin&password=x", "user=admin&password=x"},
+        {"top level", `{"userId":"ci","password":"s3cr3t"}`, `{"password":"REDACTED","userId":"ci"}`},
+        {
+            "nested and in arrays",
+            `{"httpClient":{"authentication":{"username":"ci","password":"p","bearerToken":"t"}},"users":[{"apiKey":"k"}]}`,
+            `{"httpClient":{"authentication":{"bearerToken":"REDACTED","password":"REDACTED","username":"ci"}},"users":[{"apiKey":"REDACTED"}]}`,
+        },
+        {"signing key", `{"aptSigning":{"keypair":"-----BEGIN PGP","passphrase":"p"}}`, `{"aptSigning":{"keypair":"REDACTED","passphrase":"REDACTED"}}`},
+        {"continuation token kept", `{"items":[],"continuationToken":"abc"}`, `{"continuationToken":"abc","items":[]}`},
+        {"non-string values kept", `{"token":null,"secretCount":3}`, `{"secretCount":3,"token":null}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := redactBody([]byte(tt.body)); got != tt.want {
+                t.Errorf("redactBody() = %s, want %s", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestRedactHeaders(t *testing.T) {
+    h := http.Header{
+        "Authorization":       {"Basic YWRtaW46YWRtaW4xMjM="},
+        "Proxy-Authorization": {"Bearer abc"},
+        "Cookie":              {"NXSESSIONID=abc"},
+        "Accept":              {"application/json"},
+    }
+    want := map[string][]string{
+        "Authorization":       {"Basic REDACTED"},
+        "Proxy-Authorization": {"Bearer REDACTED"},
+        "Cookie":              {"REDACTED"},
+        "Accept":              {"application/json"},
+    }
+    if got := redactHeaders(h); !reflect.DeepEqual(got, want) {
+        t.Errorf("redactHeaders() = %v, want %v", got, want)
+    }
+    if h.Get("Authorization") != "Basic YWRtaW46YWRtaW4xMjM=" {
+        t.Error("redactHeaders changed the request headers")
+    }
+}
+
+func TestLogNeverShowsSecrets(t *testing.T) {
+    rec := &recorder{respond: func(r *http.Request) (int, string) {
+        return http.StatusOK, `{"token":"server-secret"}`
+    }}
+    for _, format := range []string{LogFormatText, LogFormatJSON} {
+        t.Run(format, func(t *testing.T) {
+            var log bytes.Buffer
+            c := newTestClient(t, rec)
+            c.username, c.password = "admin", "client-secret"
+            c.verbose = 2
+            c.SetLogOutput(&log)
+            if err := c.SetLogFormat(format); err != nil {
+                t.Fatal(err)
+            }
+
+            body := map[string]interface{}{"password": "body-secret"}
+            if _, err := c.do(context.Background(), "PUT", "/service/rest/v1/x", body); err != nil {
+                t.Fatal(err)
+            }
+            out := log.String()
+            for _, secret := range []string{"server-secret", "client-secret", "body-secret", "YWRtaW46Y2xpZW50LXNlY3JldA=="} {
+                if strings.Contains(out, secret) {
+                    t.Errorf("log shows %q:\n%s", secret, out)
+                }
+            }
+            if format == LogFormatJSON {
+                for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+                    if !json.Valid([]byte(line)) {
+                        t.Errorf("not a JSON record: %s", line)
+                    }
+                }
+            }
+        })
+    }
+}
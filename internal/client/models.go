@@ -0,0 +1,615 @@
+package client
+
+import (
+    "encoding/json"
+    "reflect"
+    "regexp"
+    "strings"
+)
+
+// Extra holds the fields of a Nexus object that the Go model does not know
+// about. They survive a decode/encode round trip, so objects read from a
+// newer Nexus can be written back without losing settings.
+type Extra map[string]json.RawMessage
+
+// ---------------- USER ---------------- //
+
+type User struct {
+    UserID        string   `json:"userId"`
+    FirstName     string   `json:"firstName,omitempty"`
+    LastName      string   `json:"lastName,omitempty"`
+    EmailAddress  string   `json:"emailAddress,omitempty"`
+    Source        string   `json:"source,omitempty"`
+    Status        string   `json:"status,omitempty"`
+    ReadOnly      bool     `json:"readOnly,omitempty"`
+    Roles         []string `json:"roles"`
+    ExternalRoles []string `json:"externalRoles,omitempty"`
+    Extra         Extra    `json:"-"`
+}
+
+func (u *User) UnmarshalJSON(data []byte) error {
+    type plain User
+    return unmarshalWithExtra(data, (*plain)(u), &u.Extra)
+}
+
+func (u User) MarshalJSON() ([]byte, error) {
+    type plain User
+    return marshalWithExtra(plain(u), u.Extra)
+}
+
+// ---------------- REPO ---------------- //
+
+// Repository is a repository as returned by the list endpoint and, with
+// all its settings filled in, by the format/type specific endpoints.
+type Repository struct {
+    Name       string                 `json:"name"`
+    Format     string                 `json:"format,omitempty"`
+    Type       string                 `json:"type,omitempty"`
+    URL        string                 `json:"url,omitempty"`
+    Online     *bool                  `json:"online,omitempty"`
+    Attributes map[string]interface{} `json:"attributes,omitempty"`
+
+    Storage         *Storage         `json:"storage,omitempty"`
+    Cleanup         *Cleanup         `json:"cleanup,omitempty"`
+    Component       *ComponentConfig `json:"component,omitempty"`
+    Proxy           *ProxyConfig     `json:"proxy,omitempty"`
+    NegativeCache   *NegativeCache   `json:"negativeCache,omitempty"`
+    HTTPClient      *HTTPClient      `json:"httpClient,omitempty"`
+    RoutingRuleName string           `json:"routingRuleName,omitempty"`
+    Group           *Group           `json:"group,omitempty"`
+
+    Maven       *MavenConfig       `json:"maven,omitempty"`
+    Docker      *DockerConfig      `json:"docker,omitempty"`
+    DockerProxy *DockerProxyConfig `json:"dockerProxy,omitempty"`
+    Apt         *AptConfig         `json:"apt,omitempty"`
+    AptSigning  *AptSigning        `json:"aptSigning,omitempty"`
+    Yum         *YumConfig         `json:"yum,omitempty"`
+    Npm         *NpmConfig         `json:"npm,omitempty"`
+    Pypi        *PypiConfig        `json:"pypi,omitempty"`
+    NugetProxy  *NugetProxyConfig  `json:"nugetProxy,omitempty"`
+
+    Extra Extra `json:"-"`
+}
+
+func (r *Repository) UnmarshalJSON(data []byte) error {
+    type plain Repository
+    return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r Repository) MarshalJSON() ([]byte, error) {
+    type plain Repository
+    return marshalWithExtra(plain(r), r.Extra)
+}
+
+type Storage struct {
+    BlobStoreName               string `json:"blobStoreName"`
+    StrictContentTypeValidation bool   `json:"strictContentTypeValidation"`
+    // WritePolicy is ALLOW, ALLOW_ONCE or DENY; hosted repositories only.
+    WritePolicy string `json:"writePolicy,omitempty"`
+    Extra       Extra  `json:"-"`
+}
+
+func (s *Storage) UnmarshalJSON(data []byte) error {
+    type plain Storage
+    return unmarshalWithExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s Storage) MarshalJSON() ([]byte, error) {
+    type plain Storage
+    return marshalWithExtra(plain(s), s.Extra)
+}
+
+type Cleanup struct {
+    PolicyNames []string `json:"policyNames"`
+    Extra       Extra    `json:"-"`
+}
+
+func (c *Cleanup) UnmarshalJSON(data []byte) error {
+    type plain Cleanup
+    return unmarshalWithExtra(data, (*plain)(c), &c.Extra)
+}
+
+func (c Cleanup) MarshalJSON() ([]byte, error) {
+    type plain Cleanup
+    return marshalWithExtra(plain(c), c.Extra)
+}
+
+type ComponentConfig struct {
+    ProprietaryComponents bool  `json:"proprietaryComponents"`
+    Extra                 Extra `json:"-"`
+}
+
+func (c *ComponentConfig) UnmarshalJSON(data []byte) error {
+    type plain ComponentConfig
+    return unmarshalWithExtra(data, (*plain)(c), &c.Extra)
+}
+
+func (c ComponentConfig) MarshalJSON() ([]byte, error) {
+    type plain ComponentConfig
+    return marshalWithExtra(plain(c), c.Extra)
+}
+
+type ProxyConfig struct {
+    RemoteURL string `json:"remoteUrl"`
+    // ContentMaxAge and MetadataMaxAge are in minutes; -1 caches forever.
+    ContentMaxAge  int   `json:"contentMaxAge"`
+    MetadataMaxAge int   `json:"metadataMaxAge"`
+    Extra          Extra `json:"-"`
+}
+
+func (p *ProxyConfig) UnmarshalJSON(data []byte) error {
+    type plain ProxyConfig
+    return unmarshalWithExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p ProxyConfig) MarshalJSON() ([]byte, error) {
+    type plain ProxyConfig
+    return marshalWithExtra(plain(p), p.Extra)
+}
+
+type NegativeCache struct {
+    Enabled bool `json:"enabled"`
+    // TimeToLive is in minutes.
+    TimeToLive int   `json:"timeToLive"`
+    Extra      Extra `json:"-"`
+}
+
+func (n *NegativeCache) UnmarshalJSON(data []byte) error {
+    type plain NegativeCache
+    return unmarshalWithExtra(data, (*plain)(n), &n.Extra)
+}
+
+func (n NegativeCache) MarshalJSON() ([]byte, error) {
+    type plain NegativeCache
+    return marshalWithExtra(plain(n), n.Extra)
+}
+
+type HTTPClient struct {
+    Blocked        bool                `json:"blocked"`
+    AutoBlock      bool                `json:"autoBlock"`
+    Connection     *HTTPConnection     `json:"connection,omitempty"`
+    Authentication *HTTPAuthentication `json:"authentication,omitempty"`
+    Extra          Extra               `json:"-"`
+}
+
+func (h *HTTPClient) UnmarshalJSON(data []byte) error {
+    type plain HTTPClient
+    return unmarshalWithExtra(data, (*plain)(h), &h.Extra)
+}
+
+func (h HTTPClient) MarshalJSON() ([]byte, error) {
+    type plain HTTPClient
+    return marshalWithExtra(plain(h), h.Extra)
+}
+
+type HTTPConnection struct {
+    Retries         *int   `json:"retries,omitempty"`
+    UserAgentSuffix string `json:"userAgentSuffix,omitempty"`
+    // Timeout is in seconds.
+    Timeout                 *int  `json:"timeout,omitempty"`
+    EnableCircularRedirects bool  `json:"enableCircularRedirects"`
+    EnableCookies           bool  `json:"enableCookies"`
+    UseTrustStore           bool  `json:"useTrustStore"`
+    Extra                   Extra `json:"-"`
+}
+
+func (h *HTTPConnection) UnmarshalJSON(data []byte) error {
+    type plain HTTPConnection
+    return unmarshalWithExtra(data, (*plain)(h), &h.Extra)
+}
+
+func (h HTTPConnection) MarshalJSON() ([]byte, error) {
+    type plain HTTPConnection
+    return marshalWithExtra(plain(h), h.Extra)
+}
+
+type HTTPAuthentication struct {
+    // Type is username or ntlm.
+    Type       string `json:"type"`
+    Username   string `json:"username,omitempty"`
+    Password   string `json:"password,omitempty"`
+    NTLMHost   string `json:"ntlmHost,omitempty"`
+    NTLMDomain string `json:"ntlmDomain,omitempty"`
+    Extra      Extra  `json:"-"`
+}
+
+func (h *HTTPAuthentication) UnmarshalJSON(data []byte) error {
+    type plain HTTPAuthentication
+    return unmarshalWithExtra(data, (*plain)(h), &h.Extra)
+}
+
+func (h HTTPAuthentication) MarshalJSON() ([]byte, error) {
+    type plain HTTPAuthentication
+    return marshalWithExtra(plain(h), h.Extra)
+}
+
+type Group struct {
+    // MemberNames is ordered: Nexus resolves components from the first
+    // member that has them.
+    MemberNames    []string `json:"memberNames"`
+    WritableMember string   `json:"writableMember,omitempty"`
+    Extra          Extra    `json:"-"`
+}
+
+func (g *Group) UnmarshalJSON(data []byte) error {
+    type plain Group
+    return unmarshalWithExtra(data, (*plain)(g), &g.Extra)
+}
+
+func (g Group) MarshalJSON() ([]byte, error) {
+    type plain Group
+    return marshalWithExtra(plain(g), g.Extra)
+}
+
+type MavenConfig struct {
+    // VersionPolicy is RELEASE, SNAPSHOT or MIXED.
+    VersionPolicy string `json:"versionPolicy"`
+    // LayoutPolicy is STRICT or PERMISSIVE.
+    LayoutPolicy       string `json:"layoutPolicy"`
+    ContentDisposition string `json:"contentDisposition,omitempty"`
+    Extra              Extra  `json:"-"`
+}
+
+func (m *MavenConfig) UnmarshalJSON(data []byte) error {
+    type plain MavenConfig
+    return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m MavenConfig) MarshalJSON() ([]byte, error) {
+    type plain MavenConfig
+    return marshalWithExtra(plain(m), m.Extra)
+}
+
+type DockerConfig struct {
+    V1Enabled      bool   `json:"v1Enabled"`
+    ForceBasicAuth bool   `json:"forceBasicAuth"`
+    HTTPPort       *int   `json:"httpPort,omitempty"`
+    HTTPSPort      *int   `json:"httpsPort,omitempty"`
+    Subdomain      string `json:"subdomain,omitempty"`
+    Extra          Extra  `json:"-"`
+}
+
+func (d *DockerConfig) UnmarshalJSON(data []byte) error {
+    type plain DockerConfig
+    return unmarshalWithExtra(data, (*plain)(d), &d.Extra)
+}
+
+func (d DockerConfig) MarshalJSON() ([]byte, error) {
+    type plain DockerConfig
+    return marshalWithExtra(plain(d), d.Extra)
+}
+
+type DockerProxyConfig struct {
+    // IndexType is REGISTRY, HUB or CUSTOM.
+    IndexType                string   `json:"indexType"`
+    IndexURL                 string   `json:"indexUrl,omitempty"`
+    CacheForeignLayers       bool     `json:"cacheForeignLayers,omitempty"`
+    ForeignLayerURLWhitelist []string `json:"foreignLayerUrlWhitelist,omitempty"`
+    Extra                    Extra    `json:"-"`
+}
+
+func (d *DockerProxyConfig) UnmarshalJSON(data []byte) error {
+    type plain DockerProxyConfig
+    return unmarshalWithExtra(data, (*plain)(d), &d.Extra)
+}
+
+func (d DockerProxyConfig) MarshalJSON() ([]byte, error) {
+    type plain DockerProxyConfig
+    return marshalWithExtra(plain(d), d.Extra)
+}
+
+type AptConfig struct {
+    Distribution string `json:"distribution"`
+    Flat         bool   `json:"flat,omitempty"`
+    Extra        Extra  `json:"-"`
+}
+
+func (a *AptConfig) UnmarshalJSON(data []byte) error {
+    type plain AptConfig
+    return unmarshalWithExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a AptConfig) MarshalJSON() ([]byte, error) {
+    type plain AptConfig
+    return marshalWithExtra(plain(a), a.Extra)
+}
+
+type AptSigning struct {
+    Keypair    string `json:"keypair"`
+    Passphrase string `json:"passphrase,omitempty"`
+    Extra      Extra  `json:"-"`
+}
+
+func (a *AptSigning) UnmarshalJSON(data []byte) error {
+    type plain AptSigning
+    return unmarshalWithExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a AptSigning) MarshalJSON() ([]byte, error) {
+    type plain AptSigning
+    return marshalWithExtra(plain(a), a.Extra)
+}
+
+type YumConfig struct {
+    RepodataDepth int    `json:"repodataDepth"`
+    DeployPolicy  string `json:"deployPolicy,omitempty"`
+    Extra         Extra  `json:"-"`
+}
+
+func (y *YumConfig) UnmarshalJSON(data []byte) error {
+    type plain YumConfig
+    return unmarshalWithExtra(data, (*plain)(y), &y.Extra)
+}
+
+func (y YumConfig) MarshalJSON() ([]byte, error) {
+    type plain YumConfig
+    return marshalWithExtra(plain(y), y.Extra)
+}
+
+// NpmConfig and PypiConfig control the firewall integration of npm and
+// PyPI proxies.
+type NpmConfig struct {
+    RemoveNonCataloged bool  `json:"removeNonCataloged"`
+    RemoveQuarantined  bool  `json:"removeQuarantined"`
+    Extra              Extra `json:"-"`
+}
+
+func (n *NpmConfig) UnmarshalJSON(data []byte) error {
+    type plain NpmConfig
+    return unmarshalWithExtra(data, (*plain)(n), &n.Extra)
+}
+
+func (n NpmConfig) MarshalJSON() ([]byte, error) {
+    type plain NpmConfig
+    return marshalWithExtra(plain(n), n.Extra)
+}
+
+type PypiConfig struct {
+    RemoveQuarantined bool  `json:"removeQuarantined"`
+    Extra             Extra `json:"-"`
+}
+
+func (p *PypiConfig) UnmarshalJSON(data []byte) error {
+    type plain PypiConfig
+    return unmarshalWithExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p PypiConfig) MarshalJSON() ([]byte, error) {
+    type plain PypiConfig
+    return marshalWithExtra(plain(p), p.Extra)
+}
+
+type NugetProxyConfig struct {
+    // QueryCacheItemMaxAge is in seconds.
+    QueryCacheItemMaxAge int `json:"queryCacheItemMaxAge"`
+    // NugetVersion is V2 or V3.
+    NugetVersion string `json:"nugetVersion"`
+    Extra        Extra  `json:"-"`
+}
+
+func (n *NugetProxyConfig) UnmarshalJSON(data []byte) error {
+    type plain NugetProxyConfig
+    return unmarshalWithExtra(data, (*plain)(n), &n.Extra)
+}
+
+func (n NugetProxyConfig) MarshalJSON() ([]byte, error) {
+    type plain NugetProxyConfig
+    return marshalWithExtra(plain(n), n.Extra)
+}
+
+// ---------------- BLOB ---------------- //
+
+type BlobStore struct {
+    Name                  string     `json:"name"`
+    Type                  string     `json:"type"`
+    Path                  string     `json:"path,omitempty"`
+    Unavailable           bool       `json:"unavailable"`
+    BlobCount             int64      `json:"blobCount"`
+    TotalSizeInBytes      int64      `json:"totalSizeInBytes"`
+    AvailableSpaceInBytes int64      `json:"availableSpaceInBytes"`
+    SoftQuota             *SoftQuota `json:"softQuota,omitempty"`
+    Extra                 Extra      `json:"-"`
+}
+
+func (b *BlobStore) UnmarshalJSON(data []byte) error {
+    type plain BlobStore
+    return unmarshalWithExtra(data, (*plain)(b), &b.Extra)
+}
+
+func (b BlobStore) MarshalJSON() ([]byte, error) {
+    type plain BlobStore
+    return marshalWithExtra(plain(b), b.Extra)
+}
+
+type SoftQuota struct {
+    // Type is spaceRemainingQuota or spaceUsedQuota.
+    Type string `json:"type"`
+    // Limit is in bytes.
+    Limit int64 `json:"limit"`
+    Extra Extra `json:"-"`
+}
+
+func (s *SoftQuota) UnmarshalJSON(data []byte) error {
+    type plain SoftQuota
+    return unmarshalWithExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s SoftQuota) MarshalJSON() ([]byte, error) {
+    type plain SoftQuota
+    return marshalWithExtra(plain(s), s.Extra)
+}
+
+// ---------------- COMPONENT ---------------- //
+
+type Component struct {
+    ID         string  `json:"id"`
+    Repository string  `json:"repository"`
+    Format     string  `json:"format"`
+    Group      string  `json:"group,omitempty"`
+    Name       string  `json:"name"`
+    Version    string  `json:"version,omitempty"`
+    Assets     []Asset `json:"assets,omitempty"`
+    Extra      Extra   `json:"-"`
+}
+
+func (c *Component) UnmarshalJSON(data []byte) error {
+    type plain Component
+    return unmarshalWithExtra(data, (*plain)(c), &c.Extra)
+}
+
+func (c Component) MarshalJSON() ([]byte, error) {
+    type plain Component
+    return marshalWithExtra(plain(c), c.Extra)
+}
+
+type Asset struct {
+    ID             string            `json:"id"`
+    Path           string            `json:"path"`
+    DownloadURL    string            `json:"downloadUrl,omitempty"`
+    Repository     string            `json:"repository"`
+    Format         string            `json:"format"`
+    Checksum       map[string]string `json:"checksum,omitempty"`
+    ContentType    string            `json:"contentType,omitempty"`
+    LastModified   string            `json:"lastModified,omitempty"`
+    LastDownloaded string            `json:"lastDownloaded,omitempty"`
+    BlobCreated    string            `json:"blobCreated,omitempty"`
+    Uploader       string            `json:"uploader,omitempty"`
+    UploaderIP     string            `json:"uploaderIp,omitempty"`
+    FileSize       int64             `json:"fileSize"`
+    Extra          Extra             `json:"-"`
+}
+
+func (a *Asset) UnmarshalJSON(data []byte) error {
+    type plain Asset
+    return unmarshalWithExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a Asset) MarshalJSON() ([]byte, error) {
+    type plain Asset
+    return marshalWithExtra(plain(a), a.Extra)
+}
+
+// ---------------- TASK ---------------- //
+
+type Task struct {
+    ID            string `json:"id"`
+    Name          string `json:"name"`
+    Type          string `json:"type"`
+    Message       string `json:"message,omitempty"`
+    CurrentState  string `json:"currentState"`
+    LastRunResult string `json:"lastRunResult,omitempty"`
+    NextRun       string `json:"nextRun,omitempty"`
+    LastRun       string `json:"lastRun,omitempty"`
+    Extra         Extra  `json:"-"`
+}
+
+func (t *Task) UnmarshalJSON(data []byte) error {
+    type plain Task
+    return unmarshalWithExtra(data, (*plain)(t), &t.Extra)
+}
+
+func (t Task) MarshalJSON() ([]byte, error) {
+    type plain Task
+    return marshalWithExtra(plain(t), t.Extra)
+}
+
+// ---------------- STATUS ---------------- //
+
+// ServerInfo describes a Nexus instance. Version and Edition come from the
+// Server header ("Nexus/3.61.0-02 (OSS)") and are empty when the server
+// does not send it.
+type ServerInfo struct {
+    Version string                 `json:"version"`
+    Edition string                 `json:"edition"`
+    Checks  map[string]StatusCheck `json:"checks,omitempty"`
+}
+
+// StatusCheck is one entry of the system status checks.
+type StatusCheck struct {
+    Healthy bool   `json:"healthy"`
+    Message string `json:"message,omitempty"`
+    Extra   Extra  `json:"-"`
+}
+
+func (s *StatusCheck) UnmarshalJSON(data []byte) error {
+    type plain StatusCheck
+    return unmarshalWithExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s StatusCheck) MarshalJSON() ([]byte, error) {
+    type plain StatusCheck
+    return marshalWithExtra(plain(s), s.Extra)
+}
+
+var serverHeader = regexp.MustCompile(`Nexus/(\S+)(?:\s+\(([^)]+)\))?`)
+
+func parseServerHeader(header string) *ServerInfo {
+    info := &ServerInfo{}
+    if m := serverHeader.FindStringSubmatch(header); m != nil {
+        info.Version = m[1]
+        info.Edition = m[2]
+    }
+    return info
+}
+
+// ---------------- HELPERS ---------------- //
+
+// unmarshalWithExtra decodes data into v and collects the keys v has no
+// field for into extra.
+func unmarshalWithExtra(data []byte, v interface{}, extra *Extra) error {
+    if err := json.Unmarshal(data, v); err != nil {
+        return err
+    }
+    var all map[string]json.RawMessage
+    if err := json.Unmarshal(data, &all); err != nil {
+        return err
+    }
+    for _, name := range jsonFieldNames(v) {
+        delete(all, name)
+    }
+    if len(all) == 0 {
+        *extra = nil
+        return nil
+    }
+    *extra = all
+    return nil
+}
+
+// marshalWithExtra encodes v and merges the extra fields back in. Known
+// fields win over extra ones with the same name.
+func marshalWithExtra(v interface{}, extra Extra) ([]byte, error) {
+    data, err := json.Marshal(v)
+    if err != nil || len(extra) == 0 {
+        return data, err
+    }
+    var all map[string]json.RawMessage
+    if err := json.Unmarshal(data, &all); err != nil {
+        return nil, err
+    }
+    for k, raw := range extra {
+        if _, ok := all[k]; !ok {
+            all[k] = raw
+        }
+    }
+    return json.Marshal(all)
+}
+
+func jsonFieldNames(v interface{}) []string {
+    t := reflect.TypeOf(v)
+    for t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    names := []string{}
+    for i := 0; i < t.NumField(); i++ {
+        tag := t.Field(i).Tag.Get("json")
+        name := strings.Split(tag, ",")[0]
+        if name == "-" {
+            continue
+        }
+        if name == "" {
+            name = t.Field(i).Name
+        }
+        names = append(names, name)
+    }
+    return names
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+    "encoding/json"
+    "reflect"
+    "testing"
+)
+
+func TestModelsKeepUnknownFields(t *testing.T) {
+    tests := []struct {
+        name string
+        into interface{}
+        data string
+    }{
+        {"user", &User{}, `{"userId":"ci","roles":["nx-admin"],"externalId":"x1"}`},
+        {
+            "repository with nested sections",
+            &Repository{},
+            `{"name":"maven-central","online":true,"future":{"a":1},
+              "storage":{"blobStoreName":"default","strictContentTypeValidation":true,"dataStoreName":"nexus"},
+              "proxy":{"remoteUrl":"https://repo1.maven.org/maven2/","contentMaxAge":-1,"metadataMaxAge":1440,"newProxyFlag":true},
+              "httpClient":{"blocked":false,"autoBlock":true,
+                "connection":{"retries":2,"keepAlive":true},
+                "authentication":{"type":"username","username":"ci","password":"s3cr3t","bearerToken":"t"}},
+              "maven":{"versionPolicy":"RELEASE","layoutPolicy":"STRICT","checksumPolicy":"WARN"},
+              "cleanup":{"policyNames":["weekly"],"retain":3}}`,
+        },
+        {
+            "blob store with soft quota",
+            &BlobStore{},
+            `{"name":"default","type":"File","softQuota":{"type":"spaceRemainingQuota","limit":100,"unit":"MB"},"path":"/data"}`,
+        },
+        {"component", &Component{}, `{"id":"42","repository":"r","format":"maven2","name":"lib","assets":[{"path":"a.jar","attributes":{"x":1}}],"new":"y"}`},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if err := json.Unmarshal([]byte(tt.data), tt.into); err != nil {
+                t.Fatal(err)
+            }
+            out, err := json.Marshal(tt.into)
+            if err != nil {
+                t.Fatal(err)
+            }
+            var want, got interface{}
+            json.Unmarshal([]byte(tt.data), &want)
+            json.Unmarshal(out, &got)
+            if !subset(want, got) {
+                t.Errorf("round trip lost fields\n got: %s\nwant: %s", out, tt.data)
+            }
+        })
+    }
+}
+
+// subset reports whether every value in want is also in got. Fields the
+// models add with their zero value are ignored.
+func subset(want, got interface{}) bool {
+    switch w := want.(type) {
+    case map[string]interface{}:
+        g, ok := got.(map[string]interface{})
+        if !ok {
+            return false
+        }
+        for k, v := range w {
+            if !subset(v, g[k]) {
+                return false
+            }
+        }
+        return true
+    case []interface{}:
+        g, ok := got.([]interface{})
+        if !ok || len(g) != len(w) {
+            return false
+        }
+        for i := range w {
+            if !subset(w[i], g[i]) {
+                return false
+            }
+        }
+        return true
+    }
+    return reflect.DeepEqual(want, got)
+}
+
+func TestKnownFieldsWinOverExtra(t *testing.T) {
+    s := Storage{
+        BlobStoreName: "new",
+        Extra:         Extra{"blobStoreName": json.RawMessage(`"old"`), "dataStoreName": json.RawMessage(`"nexus"`)},
+    }
+    out, err := json.Marshal(s)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var got map[string]interface{}
+    json.Unmarshal(out, &got)
+    if got["blobStoreName"] != "new" || got["dataStoreName"] != "nexus" {
+        t.Errorf("got %s", out)
+    }
+}
+
+func TestParseServerHeader(t *testing.T) {
+    tests := []struct {
+        header, version, edition string
+    }{
+        {"Nexus/3.68.1-02 (PRO)", "3.68.1-02", "PRO"},
+        {"Nexus/3.41.0-01 (OSS)", "3.41.0-01", "OSS"},
+        {"Nexus/3.70.0-03", "3.70.0-03", ""},
+        {"nginx", "", ""},
+        {"", "", ""},
+    }
+    for _, tt := range tests {
+        info := parseServerHeader(tt.header)
+        if info.Version != tt.version || info.Edition != tt.edition {
+            t.Errorf("parseServerHeader(%q) = %q, %q, want %q, %q", tt.header, info.Version, info.Edition, tt.version, tt.edition)
+        }
+    }
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+    "errors"
+    "io"
+    "net/http"
+    "strings"
+    "time"
+)
+
+// DefaultUserAgent is sent unless WithUserAgent overrides it.
+const DefaultUserAgent = "nexuscli"
+
+// Middleware wraps the transport of the client. It can add headers, sign
+// requests, record metrics and so on.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper, which makes
+// small middlewares one-liners.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+    return f(req)
+}
+
+// Option configures a client created with New.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+    username    string
+    password    string
+    token       string
+    timeout     time.Duration
+    verbose     int
+    httpClient  *http.Client
+    middlewares []Middleware
+    userAgent   string
+    basePath    string
+    retry       *RetryPolicy
+    tls         *TLSOptions
+    proxy       *ProxyOptions
+    logOut      io.Writer
+    logFormat   string
+}
+
+// WithBasicAuth authenticates with a username and password.
+func WithBasicAuth(username, password string) Option {
+    return func(o *clientOptions) {
+        o.username = username
+        o.password = password
+    }
+}
+
+// WithToken authenticates with a bearer token. It wins over basic auth.
+func WithToken(token string) Option {
+    return func(o *clientOptions) {
+        o.token = token
+    }
+}
+
+// WithTimeout sets the timeout of a single request. It is ignored when
+// WithHTTPClient is used; set the timeout on that client instead.
+func WithTimeout(d time.Duration) Option {
+    return func(o *clientOptions) {
+        o.timeout = d
+    }
+}
+
+// WithVerbosity sets the level of HTTP diagnostics: 1 logs requests and
+// responses, 2 adds redacted headers and bodies.
+func WithVerbosity(level int) Option {
+    return func(o *clientOptions) {
+        o.verbose = level
+    }
+}
+
+// WithHTTPClient sends requests through hc instead of a client built by
+// New. TLS and proxy options need hc's transport to be an *http.Transport
+// (or nil, meaning http.DefaultTransport).
+func WithHTTPClient(hc *http.Client) Option {
+    return func(o *clientOptions) {
+        o.httpClient = hc
+    }
+}
+
+// WithMiddleware wraps the transport in mw. The first middleware given is
+// the outermost one and sees each request first.
+func WithMiddleware(mw ...Middleware) Option {
+    return func(o *clientOptions) {
+        o.middlewares = append(o.middlewares, mw...)
+    }
+}
+
+// WithUserAgent overrides the User-Agent header.
+func WithUserAgent(ua string) Option {
+    return func(o *clientOptions) {
+        o.userAgent = ua
+    }
+}
+
+// WithBasePath sets the context path Nexus is served under, for instance
+// "/nexus" when it sits behind a reverse proxy at https://host/nexus.
+func WithBasePath(path string) Option {
+    return func(o *clientOptions) {
+        o.basePath = path
+    }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+    return func(o *clientOptions) {
+        o.retry = &p
+    }
+}
+
+// WithTLS applies TLS options to the transport.
+func WithTLS(opts TLSOptions) Option {
+    return func(o *clientOptions) {
+        o.tls = &opts
+    }
+}
+
+// WithProxy applies proxy options to the transport.
+func WithProxy(opts ProxyOptions) Option {
+    return func(o *clientOptions) {
+        o.proxy = &opts
+    }
+}
+
+// WithLogOutput sends diagnostics to w instead of stderr.
+func WithLogOutput(w io.Writer) Option {
+    return func(o *clientOptions) {
+        o.logOut = w
+    }
+}
+
+// WithLogFormat selects text or json diagnostics.
+func WithLogFormat(format string) Option {
+    return func(o *clientOptions) {
+        o.logFormat = format
+    }
+}
+
+// New creates a client for the Nexus instance at baseURL.
+//
+//  c, err := client.New("https://nexus.example.com",
+//      client.WithToken(token),
+//      client.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+//          return client.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+//              req.Header.Set("X-Gateway-Key", key)
+//              return next.RoundTrip(req)
+//          })
+//      }),
+//  )
+func New(baseURL string, opts ...Option) (*NexusClient, error) {
+    o := clientOptions{
+        timeout:   30 * time.Second,
+        userAgent: DefaultUserAgent,
+    }
+    for _, opt := range opts {
+        opt(&o)
+    }
+
+    basePath := strings.TrimSuffix(o.basePath, "/")
+    if basePath != "" && !strings.HasPrefix(basePath, "/") {
+        basePath = "/" + basePath
+    }
+
+    c := &NexusClient{
+        baseURL:   strings.TrimSuffix(baseURL, "/"),
+        basePath:  basePath,
+        username:  o.username,
+        password:  o.password,
+        token:     o.token,
+        timeout:   o.timeout,
+        verbose:   o.verbose,
+        userAgent: o.userAgent,
+        retry:     DefaultRetryPolicy,
+        logOut:    o.logOut,
+    }
+    if o.retry != nil {
+        c.retry = *o.retry
+    }
+    if err := c.SetLogFormat(o.logFormat); err != nil {
+        return nil, err
+    }
+
+    var hc http.Client
+    if o.httpClient != nil {
+        hc = *o.httpClient
+    } else {
+        hc.Timeout = o.timeout
+    }
+    switch t := hc.Transport.(type) {
+    case nil:
+        c.transport = http.DefaultTransport.(*http.Transport).Clone()
+    case *http.Transport:
+        // cloned so TLS and proxy settings never leak into the caller's transport
+        c.transport = t.Clone()
+    }
+
+    var base http.RoundTripper = c.transport
+    if c.transport == nil {
+        base = hc.Transport
+    }
+    for i := len(o.middlewares) - 1; i >= 0; i-- {
+        base = o.middlewares[i](base)
+    }
+    hc.Transport = base
+    c.client = &hc
+
+    if o.tls != nil {
+        if err := c.ConfigureTLS(*o.tls); err != nil {
+            return nil, err
+        }
+    }
+    if o.proxy != nil {
+        if err := c.ConfigureProxy(*o.proxy); err != nil {
+            return nil, err
+        }
+    }
+    return c, nil
+}
+
+// errCustomTransport is returned when transport settings are applied to a
+// client whose http.Client uses a transport New cannot configure.
+var errCustomTransport = errors.New("the client's http.Client uses a custom transport; configure TLS and proxies on it directly")
@@ -0,0 +1,193 @@
+package client
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "sync"
+    "testing"
+)
+
+// echoServer answers every request with an empty list and keeps the last
+// request it got.
+func echoServer(t *testing.T) (*httptest.Server, func() *http.Request) {
+    var mu sync.Mutex
+    var last *http.Request
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        mu.Lock()
+        last = r
+        mu.Unlock()
+        w.Write([]byte(`[]`))
+    }))
+    t.Cleanup(srv.Close)
+    return srv, func() *http.Request {
+        mu.Lock()
+        defer mu.Unlock()
+        return last
+    }
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+    srv, _ := echoServer(t)
+    var calls []string
+    record := func(name string) Middleware {
+        return func(next http.RoundTripper) http.RoundTripper {
+            return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+                calls = append(calls, name+" request")
+                resp, err := next.RoundTrip(req)
+                calls = append(calls, name+" response")
+                return resp, err
+            })
+        }
+    }
+
+    c, err := New(srv.URL,
+        WithMiddleware(record("first"), record("second")),
+        WithMiddleware(record("third")),
+    )
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := c.ListUsers(context.Background(), ListOptions{}).All(); err != nil {
+        t.Fatal(err)
+    }
+
+    want := []string{
+        "first request", "second request", "third request",
+        "third response", "second response", "first response",
+    }
+    if !reflect.DeepEqual(calls, want) {
+        t.Errorf("calls %v, want %v", calls, want)
+    }
+}
+
+func TestMiddlewareSeesAuthAndUserAgent(t *testing.T) {
+    srv, last := echoServer(t)
+    var seen http.Header
+    c, err := New(srv.URL,
+        WithToken("t0k3n"),
+        WithUserAgent("release-bot/1.0"),
+        WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+            return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+                seen = req.Header.Clone()
+                req.Header.Set("X-Gateway-Key", "k")
+                return next.RoundTrip(req)
+            })
+        }),
+    )
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := c.ListUsers(context.Background(), ListOptions{}).All(); err != nil {
+        t.Fatal(err)
+    }
+    if seen.Get("Authorization") != "Bearer t0k3n" || seen.Get("User-Agent") != "release-bot/1.0" {
+        t.Errorf("middleware saw headers %v", seen)
+    }
+    if last().Header.Get("X-Gateway-Key") != "k" {
+        t.Error("header set by the middleware did not reach the server")
+    }
+}
+
+func TestOptions(t *testing.T) {
+    tests := []struct {
+        name          string
+        baseURL       string
+        opts          []Option
+        wantPath      string
+        wantAuth      string
+        wantUserAgent string
+    }{
+        {
+            name:          "defaults",
+            wantPath:      "/service/rest/v1/security/users",
+            wantUserAgent: DefaultUserAgent,
+        },
+        {
+            name:          "base path",
+            opts:          []Option{WithBasePath("nexus/")},
+            wantPath:      "/nexus/service/rest/v1/security/users",
+            wantUserAgent: DefaultUserAgent,
+        },
+        {
+            name:          "base path with slashes and base URL with a trailing one",
+            baseURL:       "/",
+            opts:          []Option{WithBasePath("/nexus")},
+            wantPath:      "/nexus/service/rest/v1/security/users",
+            wantUserAgent: DefaultUserAgent,
+        },
+        {
+            name:          "basic auth",
+            opts:          []Option{WithBasicAuth("admin", "admin123"), WithUserAgent("ci")},
+            wantPath:      "/service/rest/v1/security/users",
+            wantAuth:      "Basic YWRtaW46YWRtaW4xMjM=",
+            wantUserAgent: "ci",
+        },
+        {
+            name:          "token wins over basic auth",
+            opts:          []Option{WithBasicAuth("admin", "admin123"), WithToken("t0k3n")},
+            wantPath:      "/service/rest/v1/security/users",
+            wantAuth:      "Bearer t0k3n",
+            wantUserAgent: DefaultUserAgent,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            srv, last := echoServer(t)
+            c, err := New(srv.URL+tt.baseURL, tt.opts...)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if _, err := c.ListUsers(context.Background(), ListOptions{}).All(); err != nil {
+                t.Fatal(err)
+            }
+            r := last()
+            if r.URL.Path != tt.wantPath {
+                t.Errorf("path %q, want %q", r.URL.Path, tt.wantPath)
+            }
+            if got := r.Header.Get("Authorization"); got != tt.wantAuth {
+                t.Errorf("Authorization %q, want %q", got, tt.wantAuth)
+            }
+            if got := r.Header.Get("User-Agent"); got != tt.wantUserAgent {
+                t.Errorf("User-Agent %q, want %q", got, tt.wantUserAgent)
+            }
+        })
+    }
+}
+
+func TestWithHTTPClient(t *testing.T) {
+    srv, _ := echoServer(t)
+
+    transport := &http.Transport{}
+    c, err := New(srv.URL,
+        WithHTTPClient(&http.Client{Transport: transport}),
+        WithTLS(TLSOptions{InsecureSkipVerify: true}),
+    )
+    if err != nil {
+        t.Fatal(err)
+    }
+    if transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify {
+        t.Error("TLS options changed the caller's transport")
+    }
+    if c.TLSConfig() == nil || !c.TLSConfig().InsecureSkipVerify {
+        t.Error("TLS options were not applied to the client's copy of the transport")
+    }
+
+    custom := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+        return http.DefaultTransport.RoundTrip(req)
+    })
+    _, err = New(srv.URL, WithHTTPClient(&http.Client{Transport: custom}), WithTLS(TLSOptions{}))
+    if !errors.Is(err, errCustomTransport) {
+        t.Errorf("TLS options with a custom transport: %v, want errCustomTransport", err)
+    }
+    c, err = New(srv.URL, WithHTTPClient(&http.Client{Transport: custom}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := c.ListUsers(context.Background(), ListOptions{}).All(); err != nil {
+        t.Errorf("request through a custom transport: %v", err)
+    }
+}
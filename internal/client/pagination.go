@@ -0,0 +1,168 @@
+package client
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "net/url"
+    "strconv"
+)
+
+// ListOptions controls how a list call walks the Nexus API.
+type ListOptions struct {
+    // Limit caps the number of items yielded. Zero means no limit.
+    Limit int
+    // PageSize is sent as a page size hint. Zero leaves it to the server.
+    PageSize int
+}
+
+// page is the envelope Nexus uses for continuation-token paged endpoints.
+type page[T any] struct {
+    Items             []T    `json:"items"`
+    ContinuationToken string `json:"continuationToken"`
+}
+
+// Pager lazily walks every page of a list endpoint. Pages are only fetched
+// when the items of the previous one have been consumed, so callers that
+// stop early never pay for the rest of the result set.
+//
+//  p := c.ListComponents(ctx, "maven-releases", client.ListOptions{})
+//  for p.Next() {
+//      fmt.Println(p.Item().Name)
+//  }
+//  if err := p.Err(); err != nil { ... }
+//
+// Endpoints that return a bare JSON array are treated as a single page.
+// The pager stops with the context's error once ctx is cancelled.
+type Pager[T any] struct {
+    ctx     context.Context
+    client  *NexusClient
+    path    string
+    query   url.Values
+    opts    ListOptions
+    items   []T
+    current T
+    token   string
+    fetched bool
+    done    bool
+    count   int
+    err     error
+}
+
+func newPager[T any](ctx context.Context, c *NexusClient, path string, query url.Values, opts ListOptions) *Pager[T] {
+    if query == nil {
+        query = url.Values{}
+    }
+    return &Pager[T]{
+        ctx:    ctx,
+        client: c,
+        path:   path,
+        query:  query,
+        opts:   opts,
+    }
+}
+
+// Next advances to the next item, fetching a new page when needed. It
+// returns false once the results are exhausted, the limit is reached,
+// Stop was called or an error occurred.
+func (p *Pager[T]) Next() bool {
+    if p.done || p.err != nil {
+        return false
+    }
+    if p.opts.Limit > 0 && p.count >= p.opts.Limit {
+        p.Stop()
+        return false
+    }
+    for len(p.items) == 0 {
+        if p.fetched && p.token == "" {
+            p.done = true
+            return false
+        }
+        if err := p.fetch(); err != nil {
+            p.err = err
+            return false
+        }
+    }
+    p.current = p.items[0]
+    p.items = p.items[1:]
+    p.count++
+    return true
+}
+
+// Item returns the item Next advanced to.
+func (p *Pager[T]) Item() T {
+    return p.current
+}
+
+// Buffered returns how many items Next can still return before it has to
+// request the next page. Callers that stream output flush when it is 0.
+func (p *Pager[T]) Buffered() int {
+    return len(p.items)
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (p *Pager[T]) Err() error {
+    return p.err
+}
+
+// Stop ends the iteration; no further pages are requested.
+func (p *Pager[T]) Stop() {
+    p.done = true
+    p.items = nil
+}
+
+// All drains the pager and returns every remaining item.
+func (p *Pager[T]) All() ([]T, error) {
+    items := []T{}
+    for p.Next() {
+        items = append(items, p.Item())
+    }
+    return items, p.Err()
+}
+
+func (p *Pager[T]) fetch() error {
+    q := url.Values{}
+    for k, v := range p.query {
+        q[k] = v
+    }
+    if p.token != "" {
+        q.Set("continuationToken", p.token)
+    }
+    if p.opts.PageSize > 0 {
+        q.Set("pageSize", strconv.Itoa(p.opts.PageSize))
+    }
+
+    path := p.path
+    if len(q) > 0 {
+        path += "?" + q.Encode()
+    }
+    data, err := p.client.get(p.ctx, path)
+    if err != nil {
+        return err
+    }
+    p.fetched = true
+
+    trimmed := bytes.TrimSpace(data)
+    if len(trimmed) > 0 && trimmed[0] == '[' {
+        var items []T
+        if err := json.Unmarshal(trimmed, &items); err != nil {
+            return err
+        }
+        p.items = items
+        p.token = ""
+        return nil
+    }
+
+    var pg page[T]
+    if err := json.Unmarshal(trimmed, &pg); err != nil {
+        return err
+    }
+    p.items = pg.Items
+    if pg.ContinuationToken == p.token {
+        // a server handing back the same token would loop forever
+        p.token = ""
+    } else {
+        p.token = pg.ContinuationToken
+    }
+    return nil
+}
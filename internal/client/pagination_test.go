@@ -0,0 +1,219 @@
+package client
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "sync"
+    "testing"
+    "time"
+)
+
+// recorder is an httptest handler that answers with a fixed body per
+// request and remembers the query strings it was sent.
+type recorder struct {
+    mu      sync.Mutex
+    queries []string
+    respond func(r *http.Request) (int, string)
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    rec.mu.Lock()
+    rec.queries = append(rec.queries, r.URL.RawQuery)
+    rec.mu.Unlock()
+    status, body := rec.respond(r)
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    w.Write([]byte(body))
+}
+
+func (rec *recorder) requests() int {
+    rec.mu.Lock()
+    defer rec.mu.Unlock()
+    return len(rec.queries)
+}
+
+// newTestClient starts a server running rec and returns a client for it
+// that retries quickly.
+func newTestClient(t *testing.T, rec *recorder) *NexusClient {
+    t.Helper()
+    srv := httptest.NewServer(rec)
+    t.Cleanup(srv.Close)
+    c, err := New(srv.URL, WithRetryPolicy(RetryPolicy{
+        MaxRetries: 2,
+        BaseWait:   time.Millisecond,
+        MaxWait:    time.Millisecond,
+    }))
+    if err != nil {
+        t.Fatal(err)
+    }
+    return c
+}
+
+func TestPager(t *testing.T) {
+    tests := []struct {
+        name         string
+        pages        map[string]string
+        opts         ListOptions
+        want         []string
+        wantRequests int
+    }{
+        {
+            name: "follows continuation tokens",
+            pages: map[string]string{
+                "":   `{"items":[{"name":"a"},{"name":"b"}],"continuationToken":"t1"}`,
+                "t1": `{"items":[{"name":"c"}],"continuationToken":"t2"}`,
+                "t2": `{"items":[{"name":"d"}],"continuationToken":null}`,
+            },
+            want:         []string{"a", "b", "c", "d"},
+            wantRequests: 3,
+        },
+        {
+            name: "stops when the server repeats the token",
+            pages: map[string]string{
+                "":     `{"items":[{"name":"a"}],"continuationToken":"same"}`,
+                "same": `{"items":[{"name":"b"}],"continuationToken":"same"}`,
+            },
+            want:         []string{"a", "b"},
+            wantRequests: 2,
+        },
+        {
+            name: "skips empty pages",
+            pages: map[string]string{
+                "":   `{"items":[],"continuationToken":"t1"}`,
+                "t1": `{"items":[{"name":"a"}]}`,
+            },
+            want:         []string{"a"},
+            wantRequests: 2,
+        },
+        {
+            name: "treats a bare array as one page",
+            pages: map[string]string{
+                "": `[{"name":"a"},{"name":"b"}]`,
+            },
+            want:         []string{"a", "b"},
+            wantRequests: 1,
+        },
+        {
+            name: "limit stops before the next page",
+            pages: map[string]string{
+                "":   `{"items":[{"name":"a"},{"name":"b"}],"continuationToken":"t1"}`,
+                "t1": `{"items":[{"name":"c"}]}`,
+            },
+            opts:         ListOptions{Limit: 2},
+            want:         []string{"a", "b"},
+            wantRequests: 1,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rec := &recorder{respond: func(r *http.Request) (int, string) {
+                body, ok := tt.pages[r.URL.Query().Get("continuationToken")]
+                if !ok {
+                    return http.StatusBadRequest, `{"message":"unknown token"}`
+                }
+                return http.StatusOK, body
+            }}
+            c := newTestClient(t, rec)
+
+            items, err := c.ListComponents(context.Background(), "maven-releases", tt.opts).All()
+            if err != nil {
+                t.Fatal(err)
+            }
+            var names []string
+            for _, item := range items {
+                names = append(names, item.Name)
+            }
+            if !reflect.DeepEqual(names, tt.want) {
+                t.Errorf("got %v, want %v", names, tt.want)
+            }
+            if got := rec.requests(); got != tt.wantRequests {
+                t.Errorf("got %d requests, want %d", got, tt.wantRequests)
+            }
+        })
+    }
+}
+
+func TestPagerQuery(t *testing.T) {
+    rec := &recorder{respond: func(r *http.Request) (int, string) {
+        if r.URL.Query().Get("continuationToken") == "" {
+            return http.StatusOK, `{"items":[{"name":"a"}],"continuationToken":"t 1"}`
+        }
+        return http.StatusOK, `{"items":[{"name":"b"}]}`
+    }}
+    c := newTestClient(t, rec)
+
+    if _, err := c.ListComponents(context.Background(), "npm hosted", ListOptions{PageSize: 50}).All(); err != nil {
+        t.Fatal(err)
+    }
+    want := []string{
+        "pageSize=50&repository=npm+hosted",
+        "continuationToken=t+1&pageSize=50&repository=npm+hosted",
+    }
+    if !reflect.DeepEqual(rec.queries, want) {
+        t.Errorf("got queries %q, want %q", rec.queries, want)
+    }
+}
+
+func TestPagerBufferedAndStop(t *testing.T) {
+    rec := &recorder{respond: func(r *http.Request) (int, string) {
+        return http.StatusOK, `{"items":[{"name":"a"},{"name":"b"}],"continuationToken":"next"}`
+    }}
+    c := newTestClient(t, rec)
+
+    p := c.ListComponents(context.Background(), "r", ListOptions{})
+    if !p.Next() {
+        t.Fatal(p.Err())
+    }
+    if got := p.Buffered(); got != 1 {
+        t.Errorf("Buffered() = %d, want 1", got)
+    }
+    p.Stop()
+    if p.Next() {
+        t.Error("Next() after Stop() = true")
+    }
+    if got := rec.requests(); got != 1 {
+        t.Errorf("got %d requests, want 1", got)
+    }
+}
+
+func TestPagerError(t *testing.T) {
+    rec := &recorder{respond: func(r *http.Request) (int, string) {
+        if r.URL.Query().Get("continuationToken") == "" {
+            return http.StatusOK, `{"items":[{"name":"a"}],"continuationToken":"t1"}`
+        }
+        return http.StatusNotFound, `{"message":"gone"}`
+    }}
+    c := newTestClient(t, rec)
+
+    p := c.ListComponents(context.Background(), "r", ListOptions{})
+    var names []string
+    for p.Next() {
+        names = append(names, p.Item().Name)
+    }
+    if !reflect.DeepEqual(names, []string{"a"}) {
+        t.Errorf("got %v, want the first page", names)
+    }
+    if !IsNotFound(p.Err()) {
+        t.Errorf("Err() = %v, want a 404", p.Err())
+    }
+}
+
+func TestPagerCancelled(t *testing.T) {
+    rec := &recorder{respond: func(r *http.Request) (int, string) {
+        return http.StatusOK, `[]`
+    }}
+    c := newTestClient(t, rec)
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    p := c.ListComponents(ctx, "r", ListOptions{})
+    if p.Next() {
+        t.Fatal("Next() = true on a cancelled context")
+    }
+    if p.Err() == nil {
+        t.Error("Err() = nil on a cancelled context")
+    }
+}
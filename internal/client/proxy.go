@@ -0,0 +1,124 @@
+package client
+
+import (
+    "fmt"
+    "net"
+    "net/http"
+    "net/url"
+    "strings"
+)
+
+// ProxyDirect as ProxyOptions.URL makes the client bypass any proxy, even
+// one configured through HTTPS_PROXY/HTTP_PROXY.
+const ProxyDirect = "direct"
+
+// ProxyOptions selects the proxy used to reach Nexus. Explicit settings
+// take precedence over the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables.
+type ProxyOptions struct {
+    // URL of the proxy. Empty falls back to the environment, ProxyDirect
+    // disables proxying.
+    URL      string
+    Username string
+    Password string
+    // NoProxy lists hosts reached directly: host names (subdomains
+    // included), ".domain" or "*.domain" suffixes, IPs, CIDR ranges or "*".
+    NoProxy []string
+}
+
+// ConfigureProxy applies opts to the client's transport.
+func (c *NexusClient) ConfigureProxy(opts ProxyOptions) error {
+    if c.transport == nil {
+        return errCustomTransport
+    }
+    var proxyURL *url.URL
+    if opts.URL != "" && opts.URL != ProxyDirect {
+        u, err := url.Parse(opts.URL)
+        if err != nil || u.Host == "" {
+            return fmt.Errorf("invalid proxy URL %q", opts.URL)
+        }
+        if opts.Username != "" {
+            u.User = url.UserPassword(opts.Username, opts.Password)
+        }
+        proxyURL = u
+    }
+
+    c.proxyOpts = &opts
+    c.proxyURL = proxyURL
+    c.transport.Proxy = func(req *http.Request) (*url.URL, error) {
+        u, err := opts.resolve(req, proxyURL)
+        if err == nil {
+            c.logProxy(req, u)
+        }
+        return u, err
+    }
+    return nil
+}
+
+func (o ProxyOptions) resolve(req *http.Request, proxyURL *url.URL) (*url.URL, error) {
+    if matchNoProxy(req.URL.Host, o.NoProxy) {
+        return nil, nil
+    }
+    switch o.URL {
+    case ProxyDirect:
+        return nil, nil
+    case "":
+        return http.ProxyFromEnvironment(req)
+    }
+    return proxyURL, nil
+}
+
+func (c *NexusClient) logProxy(req *http.Request, proxy *url.URL) {
+    if c.verbose < 2 {
+        return
+    }
+    chosen := "direct"
+    if proxy != nil {
+        chosen = proxy.Redacted()
+    }
+    if c.logFormat == LogFormatJSON {
+        c.logRecord("proxy", map[string]interface{}{
+            "url":   req.URL.String(),
+            "proxy": chosen,
+        })
+        return
+    }
+    fmt.Fprintf(c.logWriter(), "[HTTP] Proxy for %s: %s\n", req.URL.Host, chosen)
+}
+
+// matchNoProxy reports whether hostport is excluded from proxying.
+func matchNoProxy(hostport string, patterns []string) bool {
+    host := hostport
+    if h, _, err := net.SplitHostPort(hostport); err == nil {
+        host = h
+    }
+    // an IPv6 host without a port keeps its brackets in URL.Host
+    host = strings.ToLower(strings.Trim(host, "[]"))
+    ip := net.ParseIP(host)
+
+    for _, p := range patterns {
+        p = strings.ToLower(strings.TrimSpace(p))
+        switch {
+        case p == "":
+            continue
+        case p == "*":
+            return true
+        case strings.Contains(p, "/"):
+            if _, cidr, err := net.ParseCIDR(p); err == nil && ip != nil && cidr.Contains(ip) {
+                return true
+            }
+            continue
+        }
+
+        if h, _, err := net.SplitHostPort(p); err == nil {
+            if !strings.EqualFold(p, hostport) {
+                continue
+            }
+            p = h
+        }
+        p = strings.TrimPrefix(strings.Trim(p, "[]"), "*")
+        if host == strings.TrimPrefix(p, ".") || strings.HasSuffix(host, "."+strings.TrimPrefix(p, ".")) {
+            return true
+        }
+    }
+    return false
+}
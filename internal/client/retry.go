@@ -0,0 +1,122 @@
+package client
+
+import (
+    "context"
+    "crypto/x509"
+    "errors"
+    "math/rand"
+    "net/http"
+    "strconv"
+    "time"
+)
+
+// RetryPolicy decides which failed requests are sent again and how long to
+// wait in between. Waits grow exponentially from BaseWait with full jitter
+// and never exceed MaxWait, including waits requested via Retry-After.
+type RetryPolicy struct {
+    // MaxRetries is the number of retries after the first attempt.
+    MaxRetries int
+    BaseWait   time.Duration
+    MaxWait    time.Duration
+    // RetryNonIdempotent also retries POST requests. Only enable it for
+    // calls that are safe to repeat.
+    RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is used unless the caller sets another one.
+var DefaultRetryPolicy = RetryPolicy{
+    MaxRetries: 3,
+    BaseWait:   500 * time.Millisecond,
+    MaxWait:    30 * time.Second,
+}
+
+// retryableStatus lists the statuses a load balancer or a restarting Nexus
+// answers with while the node is temporarily unavailable.
+var retryableStatus = map[int]bool{
+    http.StatusTooManyRequests:    true,
+    http.StatusBadGateway:         true,
+    http.StatusServiceUnavailable: true,
+    http.StatusGatewayTimeout:     true,
+}
+
+func isIdempotent(method string) bool {
+    switch method {
+    case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+        return true
+    }
+    return false
+}
+
+// shouldRetry reports whether a request that ended with resp or err is
+// worth sending again. attempt counts the retries already made.
+func (p RetryPolicy) shouldRetry(method string, attempt int, resp *http.Response, err error) bool {
+    if attempt >= p.MaxRetries {
+        return false
+    }
+    if !isIdempotent(method) && !p.RetryNonIdempotent {
+        return false
+    }
+    if err != nil {
+        // certificate problems will not go away by asking again
+        var unknownAuthority x509.UnknownAuthorityError
+        var invalidCert x509.CertificateInvalidError
+        var hostname x509.HostnameError
+        return !errors.As(err, &unknownAuthority) &&
+            !errors.As(err, &invalidCert) &&
+            !errors.As(err, &hostname)
+    }
+    return retryableStatus[resp.StatusCode]
+}
+
+// delay returns how long to wait before the next retry.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+    if resp != nil {
+        if wait, ok := retryAfter(resp); ok {
+            if p.MaxWait > 0 && wait > p.MaxWait {
+                return p.MaxWait
+            }
+            return wait
+        }
+    }
+
+    backoff := p.BaseWait << uint(attempt)
+    if backoff <= 0 || (p.MaxWait > 0 && backoff > p.MaxWait) {
+        backoff = p.MaxWait
+    }
+    if backoff <= 0 {
+        return 0
+    }
+    return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// retryAfter parses a Retry-After header in either of its two forms:
+// delay in seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+    value := resp.Header.Get("Retry-After")
+    if value == "" {
+        return 0, false
+    }
+    if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+        return time.Duration(secs) * time.Second, true
+    }
+    if at, err := http.ParseTime(value); err == nil {
+        wait := time.Until(at)
+        if wait < 0 {
+            wait = 0
+        }
+        return wait, true
+    }
+    return 0, false
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+    t := time.NewTimer(d)
+    defer t.Stop()
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-t.C:
+        return nil
+    }
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+    "context"
+    "crypto/x509"
+    "errors"
+    "net/http"
+    "testing"
+    "time"
+)
+
+func TestShouldRetry(t *testing.T) {
+    policy := RetryPolicy{MaxRetries: 2}
+    status := func(code int) *http.Response { return &http.Response{StatusCode: code} }
+    tests := []struct {
+        name    string
+        policy  RetryPolicy
+        method  string
+        attempt int
+        resp    *http.Response
+        err     error
+        want    bool
+    }{
+        {"503 GET", policy, "GET", 0, status(503), nil, true},
+        {"429 PUT", policy, "PUT", 1, status(429), nil, true},
+        {"502 DELETE", policy, "DELETE", 0, status(502), nil, true},
+        {"504 HEAD", policy, "HEAD", 0, status(504), nil, true},
+        {"retries used up", policy, "GET", 2, status(503), nil, false},
+        {"500 is not transient", policy, "GET", 0, status(500), nil, false},
+        {"404", policy, "GET", 0, status(404), nil, false},
+        {"200", policy, "GET", 0, status(200), nil, false},
+        {"POST", policy, "POST", 0, status(503), nil, false},
+        {"POST when allowed", RetryPolicy{MaxRetries: 2, RetryNonIdempotent: true}, "POST", 0, status(503), nil, true},
+        {"connection error", policy, "GET", 0, nil, errors.New("connection reset"), true},
+        {"unknown authority", policy, "GET", 0, nil, x509.UnknownAuthorityError{}, false},
+        {"hostname mismatch", policy, "GET", 0, nil, x509.HostnameError{Host: "nexus"}, false},
+        {"invalid certificate", policy, "GET", 0, nil, x509.CertificateInvalidError{Reason: x509.Expired}, false},
+        {"no retries", RetryPolicy{}, "GET", 0, status(503), nil, false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := tt.policy.shouldRetry(tt.method, tt.attempt, tt.resp, tt.err); got != tt.want {
+                t.Errorf("shouldRetry() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestDelay(t *testing.T) {
+    policy := RetryPolicy{BaseWait: 100 * time.Millisecond, MaxWait: time.Second}
+    retryAfter := func(value string) *http.Response {
+        return &http.Response{Header: http.Header{"Retry-After": {value}}}
+    }
+    tests := []struct {
+        name    string
+        attempt int
+        resp    *http.Response
+        max     time.Duration
+        exact   bool
+    }{
+        {name: "first retry", attempt: 0, max: 100 * time.Millisecond},
+        {name: "backoff doubles", attempt: 2, max: 400 * time.Millisecond},
+        {name: "backoff is capped", attempt: 5, max: time.Second},
+        {name: "shift overflow is capped", attempt: 80, max: time.Second},
+        {name: "no Retry-After", attempt: 1, resp: &http.Response{Header: http.Header{}}, max: 200 * time.Millisecond},
+        {name: "Retry-After seconds", attempt: 0, resp: retryAfter("0"), max: 0, exact: true},
+        {name: "Retry-After is capped", attempt: 0, resp: retryAfter("120"), max: time.Second, exact: true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            for i := 0; i < 50; i++ {
+                got := policy.delay(tt.attempt, tt.resp)
+                if got < 0 || got > tt.max || (tt.exact && got != tt.max) {
+                    t.Fatalf("delay() = %s, want at most %s (exact %v)", got, tt.max, tt.exact)
+                }
+            }
+        })
+    }
+}
+
+func TestRetryAfter(t *testing.T) {
+    tests := []struct {
+        name   string
+        value  string
+        want   time.Duration
+        wantOK bool
+    }{
+        {"missing", "", 0, false},
+        {"seconds", "7", 7 * time.Second, true},
+        {"negative", "-1", 0, false},
+        {"past date", "Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+        {"garbage", "soon", 0, false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            resp := &http.Response{Header: http.Header{}}
+            if tt.value != "" {
+                resp.Header.Set("Retry-After", tt.value)
+            }
+            got, ok := retryAfter(resp)
+            if got != tt.want || ok != tt.wantOK {
+                t.Errorf("retryAfter(%q) = %s, %v, want %s, %v", tt.value, got, ok, tt.want, tt.wantOK)
+            }
+        })
+    }
+
+    future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+    got, ok := retryAfter(&http.Response{Header: http.Header{"Retry-After": {future}}})
+    if !ok || got < 59*time.Minute || got > time.Hour {
+        t.Errorf("retryAfter(%q) = %s, %v, want about an hour", future, got, ok)
+    }
+}
+
+func TestClientRetries(t *testing.T) {
+    tests := []struct {
+        name         string
+        method       string
+        statuses     []int
+        wantStatus   int
+        wantRequests int
+    }{
+        {"recovers after 503s", "GET", []int{503, 503, 200}, 200, 3},
+        {"gives up after MaxRetries", "GET", []int{503, 503, 503, 503}, 503, 3},
+        {"honours 429", "PUT", []int{429, 204}, 204, 2},
+        {"does not retry POST", "POST", []int{503, 200}, 503, 1},
+        {"does not retry 500", "DELETE", []int{500, 200}, 500, 1},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rec := &recorder{}
+            rec.respond = func(r *http.Request) (int, string) {
+                n := rec.requests() - 1
+                if n >= len(tt.statuses) {
+                    n = len(tt.statuses) - 1
+                }
+                return tt.statuses[n], ""
+            }
+            c := newTestClient(t, rec)
+
+            _, err := c.do(context.Background(), tt.method, "/service/rest/v1/status", nil)
+            if tt.wantStatus < 300 {
+                if err != nil {
+                    t.Fatal(err)
+                }
+            } else if got := statusOf(err); got != tt.wantStatus {
+                t.Fatalf("got error %v, want status %d", err, tt.wantStatus)
+            }
+            if got := rec.requests(); got != tt.wantRequests {
+                t.Errorf("got %d requests, want %d", got, tt.wantRequests)
+            }
+        })
+    }
+}
+
+func TestClientRetryCancelled(t *testing.T) {
+    rec := &recorder{respond: func(r *http.Request) (int, string) {
+        return http.StatusServiceUnavailable, ""
+    }}
+    c := newTestClient(t, rec)
+    c.SetRetryPolicy(RetryPolicy{MaxRetries: 5, BaseWait: time.Hour, MaxWait: time.Hour})
+
+    ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+    defer cancel()
+    start := time.Now()
+    _, err := c.do(ctx, "GET", "/service/rest/v1/status", nil)
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("got %v, want the context's error", err)
+    }
+    if elapsed := time.Since(start); elapsed > 5*time.Second {
+        t.Errorf("cancelling took %s, the retry wait was not interrupted", elapsed)
+    }
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "encoding/pem"
+    "fmt"
+    "os"
+    "strings"
+)
+
+// TLSOptions configures how the client verifies Nexus and authenticates
+// itself at the TLS layer.
+type TLSOptions struct {
+    // InsecureSkipVerify disables certificate verification entirely.
+    InsecureSkipVerify bool
+    // CAFile is a PEM bundle trusted in addition to the system roots.
+    CAFile string
+    // CertFile and KeyFile hold a PEM client certificate for mTLS.
+    CertFile string
+    KeyFile  string
+    // MinVersion is the lowest accepted TLS version: 1.0, 1.1, 1.2 or 1.3.
+    MinVersion string
+}
+
+var tlsVersions = map[string]uint16{
+    "1.0": tls.VersionTLS10,
+    "1.1": tls.VersionTLS11,
+    "1.2": tls.VersionTLS12,
+    "1.3": tls.VersionTLS13,
+}
+
+// ConfigureTLS applies opts to the client's transport.
+func (c *NexusClient) ConfigureTLS(opts TLSOptions) error {
+    if c.transport == nil {
+        return errCustomTransport
+    }
+    cfg, err := opts.tlsConfig()
+    if err != nil {
+        return err
+    }
+    c.transport.TLSClientConfig = cfg
+    c.tlsOpts = &opts
+    return nil
+}
+
+// Validate reports the first TLS file in o that does not load. Unlike the
+// client, it accepts a certificate without its key or the other way round,
+// so the two can be configured one at a time.
+func (o TLSOptions) Validate() error {
+    if o.CertFile == "" || o.KeyFile == "" {
+        for _, file := range []string{o.CertFile, o.KeyFile} {
+            if file == "" {
+                continue
+            }
+            data, err := os.ReadFile(file)
+            if err != nil {
+                return fmt.Errorf("could not load client certificate: %w", err)
+            }
+            if block, _ := pem.Decode(data); block == nil {
+                return fmt.Errorf("could not load client certificate: no PEM data in %s", file)
+            }
+        }
+        o.CertFile, o.KeyFile = "", ""
+    }
+    _, err := o.tlsConfig()
+    return err
+}
+
+func (o TLSOptions) tlsConfig() (*tls.Config, error) {
+    cfg := &tls.Config{
+        MinVersion:         tls.VersionTLS12,
+        InsecureSkipVerify: o.InsecureSkipVerify,
+    }
+
+    if o.MinVersion != "" {
+        v, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(o.MinVersion), "tls")]
+        if !ok {
+            return nil, fmt.Errorf("unsupported TLS version %q (use 1.0, 1.1, 1.2 or 1.3)", o.MinVersion)
+        }
+        cfg.MinVersion = v
+    }
+
+    if o.CAFile != "" {
+        pem, err := os.ReadFile(o.CAFile)
+        if err != nil {
+            return nil, fmt.Errorf("could not read CA bundle: %w", err)
+        }
+        pool, err := x509.SystemCertPool()
+        if err != nil {
+            pool = x509.NewCertPool()
+        }
+        if !pool.AppendCertsFromPEM(pem) {
+            return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CAFile)
+        }
+        cfg.RootCAs = pool
+    }
+
+    if o.CertFile != "" || o.KeyFile != "" {
+        if o.CertFile == "" || o.KeyFile == "" {
+            return nil, fmt.Errorf("client certificate and key must be set together")
+        }
+        cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+        if err != nil {
+            return nil, fmt.Errorf("could not load client certificate: %w", err)
+        }
+        cfg.Certificates = []tls.Certificate{cert}
+    }
+
+    return cfg, nil
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+    "context"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/pem"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// writeTestCert writes a self-signed certificate and its key as PEM files
+// to dir.
+func writeTestCert(t *testing.T, dir string) (certFile, keyFile string) {
+    t.Helper()
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    tmpl := &x509.Certificate{
+        SerialNumber:          big.NewInt(1),
+        Subject:               pkix.Name{CommonName: "nexuscli test"},
+        NotBefore:             time.Now().Add(-time.Hour),
+        NotAfter:              time.Now().Add(time.Hour),
+        IsCA:                  true,
+        KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+        BasicConstraintsValid: true,
+    }
+    der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    keyDER, err := x509.MarshalECPrivateKey(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    certFile = filepath.Join(dir, "cert.pem")
+    keyFile = filepath.Join(dir, "key.pem")
+    writePEM(t, certFile, "CERTIFICATE", der)
+    writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+    return certFile, keyFile
+}
+
+func writePEM(t *testing.T, path, kind string, der []byte) {
+    t.Helper()
+    if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0600); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestTLSOptionsValidate(t *testing.T) {
+    dir := t.TempDir()
+    cert, key := writeTestCert(t, dir)
+    junk := filepath.Join(dir, "junk.pem")
+    if err := os.WriteFile(junk, []byte("not a certificate\n"), 0600); err != nil {
+        t.Fatal(err)
+    }
+    missing := filepath.Join(dir, "missing.pem")
+
+    tests := []struct {
+        name    string
+        opts    TLSOptions
+        wantErr string
+    }{
+        {name: "nothing set"},
+        {name: "CA bundle", opts: TLSOptions{CAFile: cert}},
+        {name: "missing CA bundle", opts: TLSOptions{CAFile: missing}, wantErr: "could not read CA bundle"},
+        {name: "CA bundle without certificates", opts: TLSOptions{CAFile: junk}, wantErr: "no certificates found"},
+        {name: "certificate and key", opts: TLSOptions{CertFile: cert, KeyFile: key}},
+        {name: "certificate without key yet", opts: TLSOptions{CertFile: cert}},
+        {name: "key without certificate yet", opts: TLSOptions{KeyFile: key}},
+        {name: "missing certificate", opts: TLSOptions{CertFile: missing}, wantErr: "could not load client certificate"},
+        {name: "certificate that is not PEM", opts: TLSOptions{CertFile: junk}, wantErr: "no PEM data"},
+        {name: "key that does not match", opts: TLSOptions{CertFile: cert, KeyFile: junk}, wantErr: "could not load client certificate"},
+        {name: "TLS version", opts: TLSOptions{MinVersion: "TLS1.3"}},
+        {name: "unknown TLS version", opts: TLSOptions{MinVersion: "1.4"}, wantErr: "unsupported TLS version"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := tt.opts.Validate()
+            if tt.wantErr == "" {
+                if err != nil {
+                    t.Errorf("Validate() = %v", err)
+                }
+                return
+            }
+            if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                t.Errorf("Validate() = %v, want an error about %q", err, tt.wantErr)
+            }
+        })
+    }
+}
+
+func TestClientCABundle(t *testing.T) {
+    srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Write([]byte(`[]`))
+    }))
+    defer srv.Close()
+    caFile := filepath.Join(t.TempDir(), "ca.pem")
+    writePEM(t, caFile, "CERTIFICATE", srv.Certificate().Raw)
+
+    tests := []struct {
+        name    string
+        opts    TLSOptions
+        wantErr bool
+    }{
+        {"system roots only", TLSOptions{}, true},
+        {"CA bundle", TLSOptions{CAFile: caFile}, false},
+        {"skip verification", TLSOptions{InsecureSkipVerify: true}, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            c, err := New(srv.URL, WithTLS(tt.opts), WithRetryPolicy(RetryPolicy{}))
+            if err != nil {
+                t.Fatal(err)
+            }
+            _, err = c.ListUsers(context.Background(), ListOptions{}).All()
+            if (err != nil) != tt.wantErr {
+                t.Errorf("got error %v, want error %v", err, tt.wantErr)
+            }
+        })
+    }
+}
@@ -0,0 +1,68 @@
+// Package credentials keeps Nexus secrets out of the config file. Secrets
+// live in the OS keyring, in an encrypted file for machines without one,
+// or are fetched from an external credential helper.
+package credentials
+
+import (
+    "errors"
+    "fmt"
+)
+
+// Service is the keyring service name secrets are stored under.
+const Service = "nexuscli"
+
+// Store backends accepted in the credentialStore setting.
+const (
+    BackendPlain   = "plain"
+    BackendKeyring = "keyring"
+    BackendFile    = "file"
+)
+
+// ErrNotFound is returned when no secret is stored for an account.
+var ErrNotFound = errors.New("secret not found")
+
+// Store keeps secrets by account name.
+type Store interface {
+    Get(account string) (string, error)
+    Set(account, secret string) error
+    Delete(account string) error
+    // Name is the backend name, for messages.
+    Name() string
+}
+
+// Account returns the account a secret of the given kind (password or
+// token) of a config context is stored under.
+func Account(context, kind string) string {
+    if context == "" {
+        context = "default"
+    }
+    return context + ":" + kind
+}
+
+// Open returns the store for backend. An empty backend picks the OS
+// keyring when one is reachable and the encrypted file otherwise.
+func Open(backend string) (Store, error) {
+    switch backend {
+    case "":
+        if k := newKeyring(); k != nil {
+            return k, nil
+        }
+        return openFileStore()
+    case BackendKeyring:
+        if k := newKeyring(); k != nil {
+            return k, nil
+        }
+        return nil, fmt.Errorf("no OS keyring available (needs secret-tool with a D-Bus session on Linux, or macOS)")
+    case BackendFile:
+        return openFileStore()
+    }
+    return nil, fmt.Errorf("unknown credential store %q (use keyring, file or plain)", backend)
+}
+
+func openFileStore() (Store, error) {
+    f, err := newFileStore()
+    if err != nil {
+        return nil, err
+    }
+    return f, nil
+}
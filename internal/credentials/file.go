@@ -0,0 +1,201 @@
+package credentials
+
+import (
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+
+    "golang.org/x/crypto/pbkdf2"
+)
+
+// PassphraseEnv names the variable holding the passphrase of the encrypted
+// file store. Without it a random key is kept in a 0600 file next to the
+// store; that protects secrets from leaking with the config file, but not
+// from someone who can read the user's files.
+const PassphraseEnv = "NEXUS_CREDENTIALS_PASSPHRASE"
+
+const (
+    kdfIterations = 200000
+    keyLen        = 32
+)
+
+// fileStore keeps secrets in an AES-256-GCM encrypted JSON document.
+type fileStore struct {
+    path    string
+    keyPath string
+}
+
+type encryptedFile struct {
+    Version int    `json:"version"`
+    Salt    []byte `json:"salt"`
+    Nonce   []byte `json:"nonce"`
+    Data    []byte `json:"data"`
+}
+
+func newFileStore() (*fileStore, error) {
+    dir, err := os.UserConfigDir()
+    if err != nil {
+        return nil, fmt.Errorf("could not find config dir: %w", err)
+    }
+    dir = filepath.Join(dir, "nexuscli")
+    return &fileStore{
+        path:    filepath.Join(dir, "credentials.enc"),
+        keyPath: filepath.Join(dir, "credentials.key"),
+    }, nil
+}
+
+func (f *fileStore) Name() string {
+    return BackendFile
+}
+
+func (f *fileStore) Get(account string) (string, error) {
+    secrets, err := f.load()
+    if err != nil {
+        return "", err
+    }
+    secret, ok := secrets[account]
+    if !ok {
+        return "", ErrNotFound
+    }
+    return secret, nil
+}
+
+func (f *fileStore) Set(account, secret string) error {
+    secrets, err := f.load()
+    if err != nil {
+        return err
+    }
+    secrets[account] = secret
+    return f.save(secrets)
+}
+
+func (f *fileStore) Delete(account string) error {
+    secrets, err := f.load()
+    if err != nil {
+        return err
+    }
+    if _, ok := secrets[account]; !ok {
+        return nil
+    }
+    delete(secrets, account)
+    return f.save(secrets)
+}
+
+func (f *fileStore) load() (map[string]string, error) {
+    secrets := map[string]string{}
+    raw, err := os.ReadFile(f.path)
+    if errors.Is(err, fs.ErrNotExist) {
+        return secrets, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    var enc encryptedFile
+    if err := json.Unmarshal(raw, &enc); err != nil {
+        return nil, fmt.Errorf("corrupt credential file %s: %w", f.path, err)
+    }
+    key, err := f.key(enc.Salt, false)
+    if err != nil {
+        return nil, err
+    }
+    gcm, err := newGCM(key)
+    if err != nil {
+        return nil, err
+    }
+    plain, err := gcm.Open(nil, enc.Nonce, enc.Data, nil)
+    if err != nil {
+        return nil, fmt.Errorf("could not decrypt %s: wrong passphrase or key", f.path)
+    }
+    if err := json.Unmarshal(plain, &secrets); err != nil {
+        return nil, fmt.Errorf("corrupt credential file %s: %w", f.path, err)
+    }
+    return secrets, nil
+}
+
+func (f *fileStore) save(secrets map[string]string) error {
+    plain, err := json.Marshal(secrets)
+    if err != nil {
+        return err
+    }
+    salt := make([]byte, 16)
+    if _, err := rand.Read(salt); err != nil {
+        return err
+    }
+    key, err := f.key(salt, true)
+    if err != nil {
+        return err
+    }
+    gcm, err := newGCM(key)
+    if err != nil {
+        return err
+    }
+    nonce := make([]byte, gcm.NonceSize())
+    if _, err := rand.Read(nonce); err != nil {
+        return err
+    }
+
+    data, err := json.Marshal(encryptedFile{
+        Version: 1,
+        Salt:    salt,
+        Nonce:   nonce,
+        Data:    gcm.Seal(nil, nonce, plain, nil),
+    })
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+        return err
+    }
+    tmp := f.path + ".tmp"
+    if err := os.WriteFile(tmp, data, 0600); err != nil {
+        return err
+    }
+    return os.Rename(tmp, f.path)
+}
+
+// key derives the encryption key from the passphrase in PassphraseEnv, or
+// reads (and with create set, generates) the random key file.
+func (f *fileStore) key(salt []byte, create bool) ([]byte, error) {
+    if pass := os.Getenv(PassphraseEnv); pass != "" {
+        return pbkdf2.Key([]byte(pass), salt, kdfIterations, keyLen, sha256.New), nil
+    }
+
+    key, err := os.ReadFile(f.keyPath)
+    if err == nil && len(key) == keyLen {
+        return key, nil
+    }
+    if err != nil && !errors.Is(err, fs.ErrNotExist) {
+        return nil, err
+    }
+    if !create {
+        return nil, fmt.Errorf("credential key %s is missing; set %s if the file was created with a passphrase", f.keyPath, PassphraseEnv)
+    }
+
+    key = make([]byte, keyLen)
+    if _, err := rand.Read(key); err != nil {
+        return nil, err
+    }
+    if err := os.MkdirAll(filepath.Dir(f.keyPath), 0700); err != nil {
+        return nil, err
+    }
+    if err := os.WriteFile(f.keyPath, key, 0600); err != nil {
+        return nil, err
+    }
+    return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+    block, err := aes.NewCipher(key)
+    if err != nil {
+        return nil, err
+    }
+    return cipher.NewGCM(block)
+}
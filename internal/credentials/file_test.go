@@ -0,0 +1,93 @@
+package credentials
+
+import (
+    "errors"
+    "os"
+    "strings"
+    "testing"
+)
+
+// newTestFileStore returns a file store under a temporary config dir.
+func newTestFileStore(t *testing.T, passphrase string) *fileStore {
+    t.Helper()
+    t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+    t.Setenv(PassphraseEnv, passphrase)
+    f, err := newFileStore()
+    if err != nil {
+        t.Fatal(err)
+    }
+    return f
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+    tests := []struct {
+        name       string
+        passphrase string
+        wantKey    bool
+    }{
+        {"generated key", "", true},
+        {"passphrase", "correct horse battery staple", false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            f := newTestFileStore(t, tt.passphrase)
+
+            if _, err := f.Get(Account("", "password")); !errors.Is(err, ErrNotFound) {
+                t.Fatalf("Get() on an empty store = %v, want ErrNotFound", err)
+            }
+            if err := f.Set(Account("", "password"), "s3cr3t"); err != nil {
+                t.Fatal(err)
+            }
+            if err := f.Set(Account("prod", "token"), "t0k3n"); err != nil {
+                t.Fatal(err)
+            }
+            for account, want := range map[string]string{"default:password": "s3cr3t", "prod:token": "t0k3n"} {
+                if got, err := f.Get(account); err != nil || got != want {
+                    t.Errorf("Get(%q) = %q, %v, want %q", account, got, err, want)
+                }
+            }
+
+            raw, err := os.ReadFile(f.path)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if strings.Contains(string(raw), "s3cr3t") || strings.Contains(string(raw), "t0k3n") {
+                t.Errorf("secrets stored in clear text: %s", raw)
+            }
+            if info, err := os.Stat(f.path); err != nil || info.Mode().Perm() != 0600 {
+                t.Errorf("credential file mode = %v, %v, want 0600", info.Mode().Perm(), err)
+            }
+            if _, err := os.Stat(f.keyPath); (err == nil) != tt.wantKey {
+                t.Errorf("key file exists = %v, want %v", err == nil, tt.wantKey)
+            }
+
+            if err := f.Delete("prod:token"); err != nil {
+                t.Fatal(err)
+            }
+            if _, err := f.Get("prod:token"); !errors.Is(err, ErrNotFound) {
+                t.Errorf("Get() after Delete() = %v, want ErrNotFound", err)
+            }
+            if got, err := f.Get("default:password"); err != nil || got != "s3cr3t" {
+                t.Errorf("Delete() removed other secrets: %q, %v", got, err)
+            }
+        })
+    }
+}
+
+func TestFileStoreWrongKey(t *testing.T) {
+    f := newTestFileStore(t, "first")
+    if err := f.Set("default:password", "s3cr3t"); err != nil {
+        t.Fatal(err)
+    }
+
+    t.Setenv(PassphraseEnv, "second")
+    if _, err := f.Get("default:password"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+        t.Errorf("Get() with another passphrase = %v, want a decryption error", err)
+    }
+
+    t.Setenv(PassphraseEnv, "")
+    if _, err := f.Get("default:password"); err == nil || !strings.Contains(err.Error(), PassphraseEnv) {
+        t.Errorf("Get() without the passphrase = %v, want a hint at %s", err, PassphraseEnv)
+    }
+}
@@ -0,0 +1,117 @@
+package credentials
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "os/exec"
+    "strings"
+)
+
+// HelperPrefix is prepended to a helper name to find its executable: the
+// helper "vault" runs nexuscli-credential-vault.
+const HelperPrefix = "nexuscli-credential-"
+
+// dockerHelperPrefix names docker credential helpers, which are run as is.
+const dockerHelperPrefix = "docker-credential-"
+
+// TokenUsername is the username a helper returns when Secret is an API
+// token rather than a password.
+const TokenUsername = "<token>"
+
+// HelperCredentials is the JSON document exchanged with a helper. It
+// follows the docker credential helper protocol:
+//
+//  nexuscli-credential-<name> get      stdin: server URL   stdout: HelperCredentials
+//  nexuscli-credential-<name> store    stdin: HelperCredentials
+//  nexuscli-credential-<name> erase    stdin: server URL
+//
+// so existing docker-credential-* programs can be used directly by giving
+// their full name. Helpers are only looked up in PATH; a name with a path
+// in it is refused, so a config file cannot run an arbitrary program.
+type HelperCredentials struct {
+    ServerURL string `json:"ServerURL"`
+    Username  string `json:"Username"`
+    Secret    string `json:"Secret"`
+}
+
+// Helper runs an external credential helper.
+type Helper struct {
+    Name string
+}
+
+// Get asks the helper for the credentials of serverURL.
+func (h Helper) Get(serverURL string) (*HelperCredentials, error) {
+    out, err := h.run("get", serverURL)
+    if err != nil {
+        return nil, err
+    }
+    var creds HelperCredentials
+    if err := json.Unmarshal(out, &creds); err != nil {
+        return nil, fmt.Errorf("credential helper %s returned invalid output: %w", h.Name, err)
+    }
+    return &creds, nil
+}
+
+// Store hands credentials to the helper.
+func (h Helper) Store(creds HelperCredentials) error {
+    data, err := json.Marshal(creds)
+    if err != nil {
+        return err
+    }
+    _, err = h.run("store", string(data))
+    return err
+}
+
+// Erase asks the helper to forget the credentials of serverURL.
+func (h Helper) Erase(serverURL string) error {
+    _, err := h.run("erase", serverURL)
+    return err
+}
+
+// Program returns the path of the helper's executable.
+func (h Helper) Program() (string, error) {
+    if err := CheckHelperName(h.Name); err != nil {
+        return "", err
+    }
+    program := h.Name
+    if !strings.HasPrefix(program, HelperPrefix) && !strings.HasPrefix(program, dockerHelperPrefix) {
+        program = HelperPrefix + program
+    }
+    path, err := exec.LookPath(program)
+    if err != nil {
+        return "", fmt.Errorf("credential helper %s: %s not found in PATH", h.Name, program)
+    }
+    return path, nil
+}
+
+// CheckHelperName refuses helper names that are not a plain program name.
+func CheckHelperName(name string) error {
+    if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+        return fmt.Errorf("invalid credential helper %q: give the name of a %s* or %s* program in PATH, not a path", name, HelperPrefix, dockerHelperPrefix)
+    }
+    return nil
+}
+
+func (h Helper) run(action, input string) ([]byte, error) {
+    program, err := h.Program()
+    if err != nil {
+        return nil, err
+    }
+    cmd := exec.Command(program, action)
+    cmd.Stdin = strings.NewReader(input)
+    var stderr bytes.Buffer
+    cmd.Stderr = &stderr
+    out, err := cmd.Output()
+    if err != nil {
+        msg := strings.TrimSpace(stderr.String())
+        if msg == "" {
+            msg = strings.TrimSpace(string(out))
+        }
+        if msg != "" {
+            return nil, fmt.Errorf("credential helper %s %s: %v: %s", h.Name, action, err, msg)
+        }
+        return nil, fmt.Errorf("credential helper %s %s: %w", h.Name, action, err)
+    }
+    return out, nil
+}
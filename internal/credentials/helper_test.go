@@ -0,0 +1,98 @@
+package credentials
+
+import (
+    "os"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "testing"
+)
+
+func TestCheckHelperName(t *testing.T) {
+    tests := []struct {
+        name    string
+        wantErr bool
+    }{
+        {"vault", false},
+        {"nexuscli-credential-vault", false},
+        {"docker-credential-pass", false},
+        {"", true},
+        {".", true},
+        {"..", true},
+        {"/tmp/evil", true},
+        {"../bin/evil", true},
+        {`C:\evil.exe`, true},
+    }
+    for _, tt := range tests {
+        if err := CheckHelperName(tt.name); (err != nil) != tt.wantErr {
+            t.Errorf("CheckHelperName(%q) = %v, want error %v", tt.name, err, tt.wantErr)
+        }
+    }
+}
+
+// installHelper puts a shell script called program in a PATH of its own.
+func installHelper(t *testing.T, program, script string) {
+    t.Helper()
+    if runtime.GOOS == "windows" {
+        t.Skip("helper scripts need a POSIX shell")
+    }
+    dir := t.TempDir()
+    if err := os.WriteFile(filepath.Join(dir, program), []byte("#!/bin/sh\n"+script), 0700); err != nil {
+        t.Fatal(err)
+    }
+    t.Setenv("PATH", dir)
+}
+
+func TestHelperProgram(t *testing.T) {
+    installHelper(t, "nexuscli-credential-vault", "")
+    dir := os.Getenv("PATH")
+    if err := os.WriteFile(filepath.Join(dir, "docker-credential-pass"), []byte("#!/bin/sh\n"), 0700); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name    string
+        want    string
+        wantErr string
+    }{
+        {name: "vault", want: "nexuscli-credential-vault"},
+        {name: "nexuscli-credential-vault", want: "nexuscli-credential-vault"},
+        {name: "docker-credential-pass", want: "docker-credential-pass"},
+        {name: "pass", wantErr: "nexuscli-credential-pass not found in PATH"},
+        {name: filepath.Join(dir, "nexuscli-credential-vault"), wantErr: "not a path"},
+    }
+    for _, tt := range tests {
+        got, err := Helper{Name: tt.name}.Program()
+        if tt.wantErr != "" {
+            if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                t.Errorf("Program(%q) = %q, %v, want error %q", tt.name, got, err, tt.wantErr)
+            }
+            continue
+        }
+        if err != nil || got != filepath.Join(dir, tt.want) {
+            t.Errorf("Program(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+        }
+    }
+}
+
+func TestHelperGet(t *testing.T) {
+    installHelper(t, "nexuscli-credential-test", `
+read url
+case "$1" in
+get) printf '{"ServerURL":"%s","Username":"ci","Secret":"s3cr3t"}' "$url" ;;
+*) echo "unsupported $1" >&2; exit 1 ;;
+esac
+`)
+    h := Helper{Name: "test"}
+
+    creds, err := h.Get("https://nexus.example.com")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if creds.ServerURL != "https://nexus.example.com" || creds.Username != "ci" || creds.Secret != "s3cr3t" {
+        t.Errorf("Get() = %+v", creds)
+    }
+    if err := h.Erase("https://nexus.example.com"); err == nil || !strings.Contains(err.Error(), "unsupported erase") {
+        t.Errorf("Erase() = %v, want the helper's message", err)
+    }
+}
@@ -0,0 +1,155 @@
+package credentials
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "runtime"
+    "strings"
+)
+
+// keyring talks to the OS keyring through its command line tool:
+// secret-tool (libsecret, Secret Service) on Linux and security on macOS.
+// Going through the tools keeps the binary free of cgo and D-Bus code.
+type keyring struct {
+    tool string
+}
+
+func newKeyring() *keyring {
+    switch runtime.GOOS {
+    case "linux", "freebsd", "openbsd":
+        if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+            return nil
+        }
+        if path, err := exec.LookPath("secret-tool"); err == nil {
+            return &keyring{tool: path}
+        }
+    case "darwin":
+        if path, err := exec.LookPath("security"); err == nil {
+            return &keyring{tool: path}
+        }
+    }
+    return nil
+}
+
+func (k *keyring) Name() string {
+    return BackendKeyring
+}
+
+func (k *keyring) Get(account string) (string, error) {
+    var args []string
+    if runtime.GOOS == "darwin" {
+        args = []string{"find-generic-password", "-s", Service, "-a", account, "-w"}
+    } else {
+        args = []string{"lookup", "service", Service, "account", account}
+    }
+    out, err := k.run(nil, args...)
+    if isNotFound(err) {
+        return "", ErrNotFound
+    }
+    if err != nil {
+        return "", err
+    }
+    secret := strings.TrimRight(string(out), "\r\n")
+    if secret == "" {
+        return "", ErrNotFound
+    }
+    return secret, nil
+}
+
+func (k *keyring) Set(account, secret string) error {
+    if runtime.GOOS == "darwin" {
+        // the command goes to security -i on stdin, so the secret does not
+        // show up in the process list
+        if strings.ContainsAny(secret, "\r\n") {
+            return fmt.Errorf("keyring: secrets with line breaks cannot be stored")
+        }
+        line := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", shellQuote(Service), shellQuote(account), shellQuote(secret))
+        if len(line) > maxSecurityLine {
+            return fmt.Errorf("keyring: secret too long for the macOS keychain tool")
+        }
+        _, err := k.run([]byte(line), "-i")
+        return err
+    }
+    label := fmt.Sprintf("%s (%s)", Service, account)
+    _, err := k.run([]byte(secret), "store", "--label", label, "service", Service, "account", account)
+    return err
+}
+
+func (k *keyring) Delete(account string) error {
+    var args []string
+    if runtime.GOOS == "darwin" {
+        args = []string{"delete-generic-password", "-s", Service, "-a", account}
+    } else {
+        args = []string{"clear", "service", Service, "account", account}
+    }
+    _, err := k.run(nil, args...)
+    if isNotFound(err) {
+        // nothing stored
+        return nil
+    }
+    return err
+}
+
+// maxSecurityLine is the longest command line security -i reads.
+const maxSecurityLine = 4096
+
+// errSecItemNotFound is the exit status of security when the item does not
+// exist.
+const errSecItemNotFound = 44
+
+// toolError is a failure of the keyring tool.
+type toolError struct {
+    tool   string
+    status int
+    stderr string
+}
+
+func (e *toolError) Error() string {
+    if e.stderr == "" {
+        return fmt.Sprintf("keyring: %s exited with status %d", e.tool, e.status)
+    }
+    return fmt.Sprintf("keyring: %s exited with status %d: %s", e.tool, e.status, e.stderr)
+}
+
+// isNotFound tells whether err means that no secret is stored: security
+// exits with errSecItemNotFound, secret-tool exits with 1 without a
+// message. Any other failure, such as a locked keyring, a denied prompt or
+// a missing D-Bus session, is a real error.
+func isNotFound(err error) bool {
+    var te *toolError
+    if !errors.As(err, &te) {
+        return false
+    }
+    if runtime.GOOS == "darwin" {
+        return te.status == errSecItemNotFound
+    }
+    return te.status == 1 && te.stderr == ""
+}
+
+func (k *keyring) run(stdin []byte, args ...string) ([]byte, error) {
+    cmd := exec.Command(k.tool, args...)
+    if stdin != nil {
+        cmd.Stdin = bytes.NewReader(stdin)
+    }
+    var stderr bytes.Buffer
+    cmd.Stderr = &stderr
+    out, err := cmd.Output()
+    if err != nil {
+        var exitErr *exec.ExitError
+        if errors.As(err, &exitErr) {
+            return nil, &toolError{tool: filepath.Base(k.tool), status: exitErr.ExitCode(), stderr: strings.TrimSpace(stderr.String())}
+        }
+        return nil, fmt.Errorf("keyring: %v: %s", err, strings.TrimSpace(stderr.String()))
+    }
+    return out, nil
+}
+
+// shellQuote quotes s for the command line parser of security -i, which
+// follows the shell's single quote rules.
+func shellQuote(s string) string {
+    return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
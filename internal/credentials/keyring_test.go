@@ -0,0 +1,45 @@
+package credentials
+
+import (
+    "errors"
+    "fmt"
+    "runtime"
+    "testing"
+)
+
+func TestIsNotFound(t *testing.T) {
+    darwin := runtime.GOOS == "darwin"
+    tests := []struct {
+        name string
+        err  error
+        want bool
+    }{
+        {"security item not found", &toolError{tool: "security", status: errSecItemNotFound}, darwin},
+        {"secret-tool without a match", &toolError{tool: "secret-tool", status: 1}, !darwin},
+        {"locked keyring", &toolError{tool: "secret-tool", status: 1, stderr: "Cannot create an item in a locked collection"}, false},
+        {"denied prompt", &toolError{tool: "security", status: 51}, false},
+        {"wrapped", fmt.Errorf("get: %w", &toolError{tool: "secret-tool", status: 1}), !darwin},
+        {"no tool error", errors.New("exec: not found"), false},
+    }
+    for _, tt := range tests {
+        if got := isNotFound(tt.err); got != tt.want {
+            t.Errorf("%s: isNotFound() = %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestShellQuote(t *testing.T) {
+    tests := []struct {
+        in, want string
+    }{
+        {"plain", "'plain'"},
+        {"", "''"},
+        {"it's", `'it'"'"'s'`},
+        {"a b\t$c", "'a b\t$c'"},
+    }
+    for _, tt := range tests {
+        if got := shellQuote(tt.in); got != tt.want {
+            t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+        }
+    }
+}
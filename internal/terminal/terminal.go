@@ -0,0 +1,63 @@
+// Package terminal reads secrets from the controlling terminal without
+// echoing them.
+package terminal
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+)
+
+// ErrNotTerminal is returned by ReadPassword when stdin is not a terminal.
+var ErrNotTerminal = errors.New("stdin is not a terminal")
+
+// Prompt writes prompt to stderr and reads a line from in.
+func Prompt(in *bufio.Reader, prompt string) (string, error) {
+    fmt.Fprint(os.Stderr, prompt)
+    line, err := in.ReadString('\n')
+    if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+        return "", err
+    }
+    return strings.TrimRight(line, "\r\n"), nil
+}
+
+// ReadPassword writes prompt to stderr and reads a line from stdin with
+// echo turned off.
+func ReadPassword(prompt string) (string, error) {
+    fd := int(os.Stdin.Fd())
+    if !IsTerminal(fd) {
+        return "", ErrNotTerminal
+    }
+    fmt.Fprint(os.Stderr, prompt)
+    secret, err := readNoEcho(fd)
+    fmt.Fprintln(os.Stderr)
+    if err != nil {
+        return "", err
+    }
+    return strings.TrimRight(string(secret), "\r\n"), nil
+}
+
+// readLine reads up to a newline one byte at a time, so nothing after it
+// is consumed from the terminal.
+func readLine(r io.Reader) ([]byte, error) {
+    var line []byte
+    buf := make([]byte, 1)
+    for {
+        n, err := r.Read(buf)
+        if n == 1 {
+            if buf[0] == '\n' {
+                return line, nil
+            }
+            line = append(line, buf[0])
+        }
+        if err != nil {
+            if errors.Is(err, io.EOF) && len(line) > 0 {
+                return line, nil
+            }
+            return line, err
+        }
+    }
+}
@@ -0,0 +1,8 @@
+package terminal
+
+import "golang.org/x/sys/unix"
+
+const (
+    ioctlReadTermios  = unix.TCGETS
+    ioctlWriteTermios = unix.TCSETS
+)
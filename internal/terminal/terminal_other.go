@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly && !windows
+
+package terminal
+
+// IsTerminal always reports false: echo cannot be turned off here, so
+// secrets have to be passed on stdin.
+func IsTerminal(fd int) bool {
+    return false
+}
+
+func readNoEcho(fd int) ([]byte, error) {
+    return nil, ErrNotTerminal
+}
@@ -0,0 +1,32 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package terminal
+
+import (
+    "os"
+
+    "golang.org/x/sys/unix"
+)
+
+// IsTerminal reports whether fd refers to a terminal.
+func IsTerminal(fd int) bool {
+    _, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+    return err == nil
+}
+
+func readNoEcho(fd int) ([]byte, error) {
+    old, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+    if err != nil {
+        return nil, err
+    }
+    noEcho := *old
+    noEcho.Lflag &^= unix.ECHO
+    noEcho.Lflag |= unix.ICANON | unix.ISIG
+    noEcho.Iflag |= unix.ICRNL
+    if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, &noEcho); err != nil {
+        return nil, err
+    }
+    defer unix.IoctlSetTermios(fd, ioctlWriteTermios, old)
+
+    return readLine(os.NewFile(uintptr(fd), "/dev/stdin"))
+}
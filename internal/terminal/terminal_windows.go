@@ -0,0 +1,34 @@
+package terminal
+
+import (
+    "os"
+
+    "golang.org/x/sys/windows"
+)
+
+// IsTerminal reports whether fd refers to a console.
+func IsTerminal(fd int) bool {
+    var mode uint32
+    return windows.GetConsoleMode(windows.Handle(fd), &mode) == nil
+}
+
+func readNoEcho(fd int) ([]byte, error) {
+    var old uint32
+    if err := windows.GetConsoleMode(windows.Handle(fd), &old); err != nil {
+        return nil, err
+    }
+    mode := old&^windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT | windows.ENABLE_LINE_INPUT
+    if err := windows.SetConsoleMode(windows.Handle(fd), mode); err != nil {
+        return nil, err
+    }
+    defer windows.SetConsoleMode(windows.Handle(fd), old)
+
+    line, err := readLine(os.NewFile(uintptr(fd), "CONIN$"))
+    if err != nil {
+        return nil, err
+    }
+    if n := len(line); n > 0 && line[n-1] == '\r' {
+        line = line[:n-1]
+    }
+    return line, nil
+}